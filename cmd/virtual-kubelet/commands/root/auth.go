@@ -0,0 +1,103 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package root
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/request/anonymous"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	"k8s.io/apiserver/pkg/authentication/request/union"
+	x509request "k8s.io/apiserver/pkg/authentication/request/x509"
+	"k8s.io/apiserver/pkg/authentication/token/cache"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	tokenwebhook "k8s.io/apiserver/plugin/pkg/authenticator/token/webhook"
+	authzwebhook "k8s.io/apiserver/plugin/pkg/authorizer/webhook"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Cache TTLs for webhook authentication and authorization, matching the reference kubelet defaults.
+const (
+	tokenCacheTTL        = 2 * time.Minute
+	authorizedCacheTTL   = 5 * time.Minute
+	unauthorizedCacheTTL = 30 * time.Second
+)
+
+// newAuth creates the authenticator and authorizer used for the kubelet API.
+// It returns nil when the options allow all requests, which is the default.
+func newAuth(c Opts, client kubernetes.Interface) (api.Auth, error) {
+	if c.ClientCACertPath == "" && !c.AuthenticationTokenWebhook && !c.DisableAnonymousAuth && c.AuthorizationMode == AuthorizationModeAlwaysAllow {
+		return nil, nil
+	}
+
+	var authenticators []authenticator.Request
+
+	if c.ClientCACertPath != "" {
+		pool, err := loadCertPool(c.ClientCACertPath)
+		if err != nil {
+			return nil, err
+		}
+		opts := x509request.DefaultVerifyOptions()
+		opts.Roots = pool
+		authenticators = append(authenticators, x509request.New(opts, x509request.CommonNameUserConversion))
+	}
+
+	if c.AuthenticationTokenWebhook {
+		tokenAuth, err := tokenwebhook.NewFromInterface(client.AuthenticationV1beta1().TokenReviews(), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting up token authentication")
+		}
+		authenticators = append(authenticators, bearertoken.New(cache.New(tokenAuth, false, tokenCacheTTL, 0)))
+	}
+
+	if !c.DisableAnonymousAuth {
+		authenticators = append(authenticators, anonymous.NewAuthenticator())
+	}
+
+	var authz authorizer.Authorizer
+	switch c.AuthorizationMode {
+	case AuthorizationModeAlwaysAllow:
+		authz = authorizerfactory.NewAlwaysAllowAuthorizer()
+	case AuthorizationModeWebhook:
+		var err error
+		authz, err = authzwebhook.NewFromInterface(client.AuthorizationV1beta1().SubjectAccessReviews(), authorizedCacheTTL, unauthorizedCacheTTL)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting up webhook authorization")
+		}
+	default:
+		return nil, errdefs.InvalidInputf("authorization mode %q is not supported", c.AuthorizationMode)
+	}
+
+	return api.NewAuth(union.New(authenticators...), authz, c.NodeName), nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading client CA file")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errdefs.InvalidInputf("no certificates found in client CA file %q", path)
+	}
+	return pool, nil
+}
@@ -67,12 +67,21 @@ func installFlags(flags *pflag.FlagSet, c *Opts) {
 	flags.StringVar(&c.ProviderConfigPath, "provider-config", c.ProviderConfigPath, "cloud provider configuration file")
 	flags.StringVar(&c.MetricsAddr, "metrics-addr", c.MetricsAddr, "address to listen for metrics/stats requests")
 
+	flags.StringVar(&c.ClientCACertPath, "client-ca-file", c.ClientCACertPath, "authenticate kubelet API requests with client certificates signed by the CAs in this file")
+	flags.BoolVar(&c.AuthenticationTokenWebhook, "authentication-token-webhook", c.AuthenticationTokenWebhook, "authenticate kubelet API requests with bearer tokens using TokenReviews")
+	flags.BoolVar(&c.DisableAnonymousAuth, "disable-anonymous-auth", c.DisableAnonymousAuth, "reject kubelet API requests which are not authenticated")
+	flags.StringVar(&c.AuthorizationMode, "authorization-mode", c.AuthorizationMode, fmt.Sprintf("authorization mode for kubelet API requests (%s/%s)", AuthorizationModeAlwaysAllow, AuthorizationModeWebhook))
+	flags.StringVar(&c.ExecRecordingDir, "exec-recording-dir", c.ExecRecordingDir, "record the streams of exec sessions to files in this directory")
+
 	flags.StringVar(&c.TaintKey, "taint", c.TaintKey, "Set node taint key")
 	flags.BoolVar(&c.DisableTaint, "disable-taint", c.DisableTaint, "disable the virtual-kubelet node taint")
 	flags.MarkDeprecated("taint", "Taint key should now be configured using the VK_TAINT_KEY environment variable")
 
 	flags.IntVar(&c.PodSyncWorkers, "pod-sync-workers", c.PodSyncWorkers, `set the number of pod synchronization workers`)
 	flags.BoolVar(&c.EnableNodeLease, "enable-node-lease", c.EnableNodeLease, `use node leases (1.13) for node heartbeats`)
+	flags.DurationVar(&c.NodePingInterval, "node-ping-interval", c.NodePingInterval, "how often to renew the node lease, or to update the node status when node leases are disabled")
+	flags.DurationVar(&c.NodeStatusUpdateInterval, "node-status-update-interval", c.NodeStatusUpdateInterval, "how often to update the node status when node leases are enabled")
+	flags.DurationVar(&c.PodStatusPollInterval, "pod-status-poll-interval", c.PodStatusPollInterval, "how often to poll pod statuses from providers which do not notify of pod changes")
 
 	flags.StringSliceVar(&c.TraceExporters, "trace-exporter", c.TraceExporters, fmt.Sprintf("sets the tracing exporter to use, available exporters: %s", AvailableTraceExporters()))
 	flags.StringVar(&c.TraceConfig.ServiceName, "trace-service-name", c.TraceConfig.ServiceName, "sets the name of the service used to register with the trace exporter")
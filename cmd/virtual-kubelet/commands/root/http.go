@@ -22,11 +22,16 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/pkg/errors"
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
 	"github.com/virtual-kubelet/virtual-kubelet/log"
 	"github.com/virtual-kubelet/virtual-kubelet/node/api"
 	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
 // AcceptedCiphers is the list of accepted TLS ciphers, with known weak ciphers elided
@@ -43,18 +48,31 @@ var AcceptedCiphers = []uint16{
 	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
 }
 
-func loadTLSConfig(certPath, keyPath string) (*tls.Config, error) {
+func loadTLSConfig(certPath, keyPath, clientCAPath string) (*tls.Config, error) {
 	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "error loading tls certs")
 	}
 
-	return &tls.Config{
+	cfg := &tls.Config{
 		Certificates:             []tls.Certificate{cert},
 		MinVersion:               tls.VersionTLS12,
 		PreferServerCipherSuites: true,
 		CipherSuites:             AcceptedCiphers,
-	}, nil
+	}
+
+	if clientCAPath != "" {
+		pool, err := loadCertPool(clientCAPath)
+		if err != nil {
+			return nil, err
+		}
+		// Client certificates are optional, requests without one may still be
+		// authenticated by other means.
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return cfg, nil
 }
 
 func setupHTTPServer(ctx context.Context, p providers.Provider, cfg *apiServerConfig) (_ func(), retErr error) {
@@ -76,7 +94,7 @@ func setupHTTPServer(ctx context.Context, p providers.Provider, cfg *apiServerCo
 			WithField("keyPath", cfg.KeyPath).
 			Error("TLS certificates not provided, not setting up pod http server")
 	} else {
-		tlsCfg, err := loadTLSConfig(cfg.CertPath, cfg.KeyPath)
+		tlsCfg, err := loadTLSConfig(cfg.CertPath, cfg.KeyPath, cfg.ClientCACertPath)
 		if err != nil {
 			return nil, err
 		}
@@ -91,6 +109,16 @@ func setupHTTPServer(ctx context.Context, p providers.Provider, cfg *apiServerCo
 			RunInContainer:   p.RunInContainer,
 			GetContainerLogs: p.GetContainerLogs,
 			GetPods:          p.GetPods,
+			Auth:             cfg.Auth,
+		}
+		if mp, ok := p.(providers.PodMetricsProvider); ok {
+			podRoutes.GetStatsSummary = mp.GetStatsSummary
+		}
+		if cfg.PodLister != nil {
+			podRoutes.GetPod = podGetter(cfg.PodLister)
+		}
+		if cfg.ExecRecordingDir != "" {
+			podRoutes.ExecRecorder = newExecFileRecorder(cfg.ExecRecordingDir)
 		}
 		api.AttachPodRoutes(podRoutes, mux, true)
 
@@ -116,6 +144,9 @@ func setupHTTPServer(ctx context.Context, p providers.Provider, cfg *apiServerCo
 		if mp, ok := p.(providers.PodMetricsProvider); ok {
 			summaryHandlerFunc = mp.GetStatsSummary
 		}
+		// Like the read-only port of the reference kubelet, the metrics
+		// listener is plain HTTP and is not authenticated. Stats are also served
+		// on the TLS listener above, where they are.
 		podMetricsRoutes := api.PodMetricsConfig{
 			GetStatsSummary: summaryHandlerFunc,
 		}
@@ -142,10 +173,14 @@ func serveHTTP(ctx context.Context, s *http.Server, l net.Listener, name string)
 }
 
 type apiServerConfig struct {
-	CertPath    string
-	KeyPath     string
-	Addr        string
-	MetricsAddr string
+	CertPath         string
+	KeyPath          string
+	ClientCACertPath string
+	Addr             string
+	MetricsAddr      string
+	ExecRecordingDir string
+	Auth             api.Auth
+	PodLister        corev1listers.PodLister
 }
 
 func getAPIConfig(c Opts) (*apiServerConfig, error) {
@@ -154,8 +189,34 @@ func getAPIConfig(c Opts) (*apiServerConfig, error) {
 		KeyPath:  os.Getenv("APISERVER_KEY_LOCATION"),
 	}
 
+	config.ClientCACertPath = c.ClientCACertPath
 	config.Addr = fmt.Sprintf(":%d", c.ListenPort)
 	config.MetricsAddr = c.MetricsAddr
+	config.ExecRecordingDir = c.ExecRecordingDir
 
 	return &config, nil
 }
+
+// podGetter gets pods from the Kubernetes pod lister, rather than from the
+// provider, so that pod annotations are always available.
+func podGetter(l corev1listers.PodLister) api.PodGetterFunc {
+	return func(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+		pod, err := l.Pods(namespace).Get(name)
+		if k8serrors.IsNotFound(err) {
+			return nil, errdefs.AsNotFound(err)
+		}
+		return pod, err
+	}
+}
+
+// newExecFileRecorder records each exec session to a new file in dir.
+func newExecFileRecorder(dir string) api.ExecRecorderFunc {
+	return func(ctx context.Context, s api.ExecSession) (io.WriteCloser, error) {
+		name := fmt.Sprintf("%s_%s_%s_%d.log", s.Namespace, s.Pod, s.Container, s.Start.UnixNano())
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating exec session recording")
+		}
+		return f, nil
+	}
+}
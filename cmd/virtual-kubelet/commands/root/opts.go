@@ -22,6 +22,7 @@ import (
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
+	"github.com/virtual-kubelet/virtual-kubelet/node"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -38,6 +39,16 @@ const (
 
 	DefaultTaintEffect = string(corev1.TaintEffectNoSchedule)
 	DefaultTaintKey    = "virtual-kubelet.io/provider"
+
+	DefaultAuthorizationMode = AuthorizationModeAlwaysAllow
+)
+
+// Authorization modes for requests made to the kubelet API.
+const (
+	// AuthorizationModeAlwaysAllow allows all requests.
+	AuthorizationModeAlwaysAllow = "AlwaysAllow"
+	// AuthorizationModeWebhook authorizes requests with SubjectAccessReviews against the Kubernetes API server.
+	AuthorizationModeWebhook = "Webhook"
 )
 
 // Opts stores all the options for configuring the root virtual-kubelet command.
@@ -47,45 +58,63 @@ const (
 // it into `SetDefaultOpts`
 type Opts struct {
 	// Path to the kubeconfig to use to connect to the Kubernetes API server.
-	KubeConfigPath string
+	KubeConfigPath string `yaml:"kubeconfig"`
 	// Namespace to watch for pods and other resources
-	KubeNamespace string
+	KubeNamespace string `yaml:"namespace"`
 	// Domain suffix to append to search domains for the pods created by virtual-kubelet
-	KubeClusterDomain string
+	KubeClusterDomain string `yaml:"cluster-domain"`
 
-	// Sets the port to listen for requests from the Kubernetes API server
-	ListenPort int32
+	// Sets the port to listen for requests from the Kubernetes API server, set with the KUBELET_PORT environment variable
+	ListenPort int32 `yaml:"kubelet-port"`
 
 	// Node name to use when creating a node in Kubernetes
-	NodeName string
+	NodeName string `yaml:"nodename"`
 
 	// Operating system to run pods for
-	OperatingSystem string
+	OperatingSystem string `yaml:"os"`
 
-	Provider           string
-	ProviderConfigPath string
+	Provider           string `yaml:"provider"`
+	ProviderConfigPath string `yaml:"provider-config"`
 
-	TaintKey     string
-	TaintEffect  string
-	DisableTaint bool
+	TaintKey     string `yaml:"taint"`
+	TaintEffect  string `yaml:"taint-effect"`
+	DisableTaint bool   `yaml:"disable-taint"`
 
-	MetricsAddr string
+	MetricsAddr string `yaml:"metrics-addr"`
+
+	// Path to a CA bundle used to authenticate client certificates on the kubelet API
+	ClientCACertPath string `yaml:"client-ca-file"`
+	// Authenticate bearer tokens on the kubelet API with TokenReviews
+	AuthenticationTokenWebhook bool `yaml:"authentication-token-webhook"`
+	// Reject requests to the kubelet API which are not authenticated
+	DisableAnonymousAuth bool `yaml:"disable-anonymous-auth"`
+	// How requests to the kubelet API are authorized, AlwaysAllow or Webhook
+	AuthorizationMode string `yaml:"authorization-mode"`
+	// Directory to record the streams of exec sessions to, recording is disabled if empty
+	ExecRecordingDir string `yaml:"exec-recording-dir"`
 
 	// Number of workers to use to handle pod notifications
-	PodSyncWorkers       int
-	InformerResyncPeriod time.Duration
+	PodSyncWorkers       int           `yaml:"pod-sync-workers"`
+	InformerResyncPeriod time.Duration `yaml:"full-resync-period"`
 
 	// Use node leases when supported by Kubernetes (instead of node status updates)
-	EnableNodeLease bool
+	EnableNodeLease bool `yaml:"enable-node-lease"`
+
+	// How often the node lease is renewed, or the node status is updated when leases are disabled
+	NodePingInterval time.Duration `yaml:"node-ping-interval"`
+	// How often the node status is updated when node leases are enabled
+	NodeStatusUpdateInterval time.Duration `yaml:"node-status-update-interval"`
+	// How often pod statuses are polled from providers which do not notify of pod changes
+	PodStatusPollInterval time.Duration `yaml:"pod-status-poll-interval"`
 
-	TraceExporters  []string
-	TraceSampleRate string
-	TraceConfig     TracingExporterOptions
+	TraceExporters  []string               `yaml:"trace-exporter"`
+	TraceSampleRate string                 `yaml:"trace-sample-rate"`
+	TraceConfig     TracingExporterOptions `yaml:",inline"`
 
 	// Startup Timeout is how long to wait for the kubelet to start
-	StartupTimeout time.Duration
+	StartupTimeout time.Duration `yaml:"startup-timeout"`
 
-	Version string
+	Version string `yaml:"version"`
 }
 
 // SetDefaultOpts sets default options for unset values on the passed in option struct.
@@ -111,6 +140,18 @@ func SetDefaultOpts(c *Opts) error {
 		c.PodSyncWorkers = DefaultPodSyncWorkers
 	}
 
+	if c.NodePingInterval == 0 {
+		c.NodePingInterval = node.DefaultPingInterval
+	}
+
+	if c.NodeStatusUpdateInterval == 0 {
+		c.NodeStatusUpdateInterval = node.DefaultStatusUpdateInterval
+	}
+
+	if c.PodStatusPollInterval == 0 {
+		c.PodStatusPollInterval = node.DefaultStatusPollInterval
+	}
+
 	if c.TraceConfig.ServiceName == "" {
 		c.TraceConfig.ServiceName = DefaultNodeName
 	}
@@ -142,6 +183,10 @@ func SetDefaultOpts(c *Opts) error {
 		c.TaintEffect = DefaultTaintEffect
 	}
 
+	if c.AuthorizationMode == "" {
+		c.AuthorizationMode = DefaultAuthorizationMode
+	}
+
 	if c.KubeConfigPath == "" {
 		c.KubeConfigPath = os.Getenv("KUBECONFIG")
 		if c.KubeConfigPath == "" {
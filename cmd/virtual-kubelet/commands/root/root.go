@@ -22,7 +22,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
 	"github.com/virtual-kubelet/virtual-kubelet/log"
 	"github.com/virtual-kubelet/virtual-kubelet/manager"
 	"github.com/virtual-kubelet/virtual-kubelet/node"
@@ -56,6 +55,7 @@ This allows users to schedule kubernetes workloads on nodes that aren't running
 	}
 
 	installFlags(cmd.Flags(), &c)
+	cmd.AddCommand(newValidateConfigCommand(s, c))
 	return cmd
 }
 
@@ -63,12 +63,8 @@ func runRootCommand(ctx context.Context, s *providers.Store, c Opts) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	if ok := providers.ValidOperatingSystems[c.OperatingSystem]; !ok {
-		return errdefs.InvalidInputf("operating system %q is not supported", c.OperatingSystem)
-	}
-
-	if c.PodSyncWorkers == 0 {
-		return errdefs.InvalidInput("pod sync workers must be greater than 0")
+	if err := validateOpts(c); err != nil {
+		return err
 	}
 
 	var taint *corev1.Taint
@@ -115,6 +111,12 @@ func runRootCommand(ctx context.Context, s *providers.Store, c Opts) error {
 		return err
 	}
 
+	apiConfig.Auth, err = newAuth(c, client)
+	if err != nil {
+		return err
+	}
+	apiConfig.PodLister = podInformer.Lister()
+
 	if err := setupTracing(ctx, c); err != nil {
 		return err
 	}
@@ -157,6 +159,8 @@ func runRootCommand(ctx context.Context, s *providers.Store, c Opts) error {
 		pNode,
 		client.CoreV1().Nodes(),
 		node.WithNodeEnableLeaseV1Beta1(leaseClient, nil),
+		node.WithNodePingInterval(c.NodePingInterval),
+		node.WithNodeStatusUpdateInterval(c.NodeStatusUpdateInterval),
 		node.WithNodeStatusUpdateErrorHandler(func(ctx context.Context, err error) error {
 			if !k8serrors.IsNotFound(err) {
 				return err
@@ -189,6 +193,8 @@ func runRootCommand(ctx context.Context, s *providers.Store, c Opts) error {
 		SecretInformer:    secretInformer,
 		ConfigMapInformer: configMapInformer,
 		ServiceInformer:   serviceInformer,
+
+		StatusPollInterval: c.PodStatusPollInterval,
 	})
 	if err != nil {
 		return errors.Wrap(err, "error setting up pod controller")
@@ -37,12 +37,9 @@ var (
 	}
 )
 
+// setupTracing registers the configured trace exporters and sampler.
+// The options are expected to have been checked by validateOpts.
 func setupTracing(ctx context.Context, c Opts) error {
-	for k := range c.TraceConfig.Tags {
-		if reservedTagNames[k] {
-			return errdefs.InvalidInputf("invalid trace tag %q, must not use a reserved tag key", k)
-		}
-	}
 	if c.TraceConfig.Tags == nil {
 		c.TraceConfig.Tags = make(map[string]string, 3)
 	}
@@ -61,22 +58,9 @@ func setupTracing(ctx context.Context, c Opts) error {
 		octrace.RegisterExporter(exporter)
 	}
 	if len(c.TraceExporters) > 0 {
-		var s octrace.Sampler
-		switch strings.ToLower(c.TraceSampleRate) {
-		case "":
-		case "always":
-			s = octrace.AlwaysSample()
-		case "never":
-			s = octrace.NeverSample()
-		default:
-			rate, err := strconv.Atoi(c.TraceSampleRate)
-			if err != nil {
-				return errdefs.AsInvalidInput(errors.Wrap(err, "unsupported trace sample rate"))
-			}
-			if rate < 0 || rate > 100 {
-				return errdefs.AsInvalidInput(errors.Wrap(err, "trace sample rate must be between 0 and 100"))
-			}
-			s = octrace.ProbabilitySampler(float64(rate) / 100)
+		s, err := parseTraceSampleRate(c.TraceSampleRate)
+		if err != nil {
+			return err
 		}
 
 		if s != nil {
@@ -91,6 +75,28 @@ func setupTracing(ctx context.Context, c Opts) error {
 	return nil
 }
 
+// parseTraceSampleRate parses the --trace-sample-rate flag into a sampler.
+// It returns a nil sampler when the rate is not set.
+func parseTraceSampleRate(rate string) (octrace.Sampler, error) {
+	switch strings.ToLower(rate) {
+	case "":
+		return nil, nil
+	case "always":
+		return octrace.AlwaysSample(), nil
+	case "never":
+		return octrace.NeverSample(), nil
+	}
+
+	r, err := strconv.Atoi(rate)
+	if err != nil {
+		return nil, errdefs.AsInvalidInput(errors.Wrap(err, "unsupported trace sample rate"))
+	}
+	if r < 0 || r > 100 {
+		return nil, errdefs.InvalidInputf("trace sample rate must be between 0 and 100, got %d", r)
+	}
+	return octrace.ProbabilitySampler(float64(r) / 100), nil
+}
+
 func setupZpages(ctx context.Context) {
 	p := os.Getenv("ZPAGES_PORT")
 	if p == "" {
@@ -20,8 +20,8 @@ import (
 )
 
 type TracingExporterOptions struct {
-	Tags        map[string]string
-	ServiceName string
+	Tags        map[string]string `yaml:"trace-tag"`
+	ServiceName string            `yaml:"trace-service-name"`
 }
 
 var (
@@ -0,0 +1,200 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package root
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// newValidateConfigCommand creates the validate-config subcommand.
+// It takes the same flags as the root command, checks them, and prints the
+// effective configuration instead of starting the virtual-kubelet.
+func newValidateConfigCommand(s *providers.Store, c Opts) *cobra.Command {
+	var checkKubeConfig bool
+
+	cmd := &cobra.Command{
+		Use:   "validate-config",
+		Short: "Validate the configuration and print the effective options",
+		Long: `Validate the configuration and print the effective options.
+
+The provider is initialized to check its configuration file, but no pods or
+nodes are created. Exits non-zero if the configuration is invalid.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidateConfig(s, c, checkKubeConfig, cmd.OutOrStdout())
+		},
+	}
+
+	installFlags(cmd.Flags(), &c)
+	cmd.Flags().BoolVar(&checkKubeConfig, "check-kubeconfig", false, "also check that the Kubernetes API server is reachable with the kube config")
+	return cmd
+}
+
+func runValidateConfig(s *providers.Store, c Opts, checkKubeConfig bool, out io.Writer) error {
+	if err := SetDefaultOpts(&c); err != nil {
+		return err
+	}
+
+	if err := validateOpts(c); err != nil {
+		return err
+	}
+
+	if c.Provider == "" {
+		return errdefs.InvalidInput("provider must be set")
+	}
+
+	pInit := s.Get(c.Provider)
+	if pInit == nil {
+		return errdefs.NotFoundf("provider %q not found", c.Provider)
+	}
+
+	// There is no resource manager since no informers are started, providers
+	// must not rely on it at init time.
+	_, err := pInit(providers.InitConfig{
+		ConfigPath:        c.ProviderConfigPath,
+		NodeName:          c.NodeName,
+		OperatingSystem:   c.OperatingSystem,
+		DaemonPort:        c.ListenPort,
+		InternalIP:        os.Getenv("VKUBELET_POD_IP"),
+		KubeClusterDomain: c.KubeClusterDomain,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error initializing provider %s", c.Provider)
+	}
+
+	if checkKubeConfig {
+		client, err := newClient(c.KubeConfigPath)
+		if err != nil {
+			return err
+		}
+		if _, err := client.Discovery().ServerVersion(); err != nil {
+			return errors.Wrap(err, "could not reach the Kubernetes API server")
+		}
+	}
+
+	b, err := yaml.Marshal(flagValues(reflect.ValueOf(c)))
+	if err != nil {
+		return errors.Wrap(err, "error marshalling effective configuration")
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// flagValues returns the fields of the options struct v keyed by their yaml
+// tags, which match the flag names. Durations are formatted the way flags take
+// them, such as "5s", rather than as nanoseconds.
+func flagValues(v reflect.Value) yaml.MapSlice {
+	var out yaml.MapSlice
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		switch name {
+		case "", "-":
+			continue
+		case ",inline":
+			out = append(out, flagValues(v.Field(i))...)
+			continue
+		}
+
+		value := v.Field(i).Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		out = append(out, yaml.MapItem{Key: name, Value: value})
+	}
+	return out
+}
+
+// validateOpts checks the options which can be verified without talking to the
+// Kubernetes API server or initializing the provider.
+func validateOpts(c Opts) error {
+	if ok := providers.ValidOperatingSystems[c.OperatingSystem]; !ok {
+		return errdefs.InvalidInputf("operating system %q is not supported", c.OperatingSystem)
+	}
+
+	if c.PodSyncWorkers == 0 {
+		return errdefs.InvalidInput("pod sync workers must be greater than 0")
+	}
+
+	for name, d := range map[string]time.Duration{
+		"node ping interval":          c.NodePingInterval,
+		"node status update interval": c.NodeStatusUpdateInterval,
+		"pod status poll interval":    c.PodStatusPollInterval,
+	} {
+		if d < 0 {
+			return errdefs.InvalidInputf("%s must not be negative", name)
+		}
+	}
+
+	if !c.DisableTaint {
+		if _, err := getTaint(c); err != nil {
+			return err
+		}
+	}
+
+	switch c.AuthorizationMode {
+	case AuthorizationModeAlwaysAllow, AuthorizationModeWebhook:
+	default:
+		return errdefs.InvalidInputf("authorization mode %q is not supported", c.AuthorizationMode)
+	}
+
+	if c.DisableAnonymousAuth && c.ClientCACertPath == "" && !c.AuthenticationTokenWebhook {
+		return errdefs.InvalidInput("anonymous auth is disabled but no other authentication method is enabled")
+	}
+
+	if c.ClientCACertPath != "" {
+		if _, err := loadCertPool(c.ClientCACertPath); err != nil {
+			return err
+		}
+	}
+
+	if c.ExecRecordingDir != "" {
+		fi, err := os.Stat(c.ExecRecordingDir)
+		if err != nil {
+			return errors.Wrap(err, "error checking exec recording directory")
+		}
+		if !fi.IsDir() {
+			return errdefs.InvalidInputf("exec recording path %q is not a directory", c.ExecRecordingDir)
+		}
+	}
+
+	for k := range c.TraceConfig.Tags {
+		if reservedTagNames[k] {
+			return errdefs.InvalidInputf("invalid trace tag %q, must not use a reserved tag key", k)
+		}
+	}
+
+	for _, e := range c.TraceExporters {
+		if _, ok := tracingExporters[e]; !ok && e != "zpages" {
+			return errdefs.NotFoundf("tracing exporter %q not found", e)
+		}
+	}
+
+	if _, err := parseTraceSampleRate(c.TraceSampleRate); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package root
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+)
+
+func TestValidateConfig(t *testing.T) {
+	s := providers.NewStore()
+	s.Register("fail", func(cfg providers.InitConfig) (providers.Provider, error) {
+		return nil, errdefs.InvalidInputf("bad config file %q", cfg.ConfigPath)
+	})
+	s.Register("ok", func(cfg providers.InitConfig) (providers.Provider, error) {
+		return nil, nil
+	})
+
+	var out bytes.Buffer
+	if err := runValidateConfig(s, Opts{Provider: "ok", NodeName: "vk-test"}, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "nodename: vk-test") {
+		t.Fatalf("expected the effective config to contain the node name, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "pod-sync-workers: 10") {
+		t.Fatalf("expected the effective config to contain defaulted values, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "pod-status-poll-interval: 5s") {
+		t.Fatalf("expected durations in the effective config to be formatted like flags, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "trace-service-name: ") {
+		t.Fatalf("expected the effective config to contain the tracing options, got:\n%s", out.String())
+	}
+
+	err := runValidateConfig(s, Opts{Provider: "fail", ProviderConfigPath: "/bad.json"}, false, &out)
+	if !errdefs.IsInvalidInput(err) {
+		t.Fatalf("expected invalid input error, got: %v", err)
+	}
+
+	err = runValidateConfig(s, Opts{Provider: "notexist"}, false, &out)
+	if !errdefs.IsNotFound(err) {
+		t.Fatalf("expected not found error, got: %v", err)
+	}
+
+	err = runValidateConfig(s, Opts{Provider: "ok", OperatingSystem: "Plan9"}, false, &out)
+	if !errdefs.IsInvalidInput(err) {
+		t.Fatalf("expected invalid input error, got: %v", err)
+	}
+
+	for _, rate := range []string{"sometimes", "101", "-1"} {
+		err = runValidateConfig(s, Opts{Provider: "ok", TraceSampleRate: rate}, false, &out)
+		if !errdefs.IsInvalidInput(err) {
+			t.Fatalf("expected invalid input error for trace sample rate %q, got: %v", rate, err)
+		}
+	}
+
+	err = runValidateConfig(s, Opts{Provider: "ok", ClientCACertPath: "/does/not/exist.pem"}, false, &out)
+	if err == nil {
+		t.Fatal("expected an error for an unreadable client CA file")
+	}
+
+	emptyCA, err := ioutil.TempFile("", "client-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyCA.Close()
+	defer os.Remove(emptyCA.Name())
+	err = runValidateConfig(s, Opts{Provider: "ok", ClientCACertPath: emptyCA.Name()}, false, &out)
+	if !errdefs.IsInvalidInput(err) {
+		t.Fatalf("expected invalid input error for an empty client CA file, got: %v", err)
+	}
+}
@@ -59,6 +59,7 @@ func main() {
 
 	s := providers.NewStore()
 	registerMock(s)
+	registerComposite(s)
 
 	rootCmd := root.NewCommand(ctx, filepath.Base(os.Args[0]), s, opts)
 	rootCmd.AddCommand(version.NewCommand(buildVersion, buildTime), cmdproviders.NewCommand(s))
@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	"github.com/virtual-kubelet/virtual-kubelet/providers/composite"
 	"github.com/virtual-kubelet/virtual-kubelet/providers/mock"
 )
 
@@ -26,3 +27,15 @@ func registerMock(s *providers.Store) {
 		)
 	})
 }
+
+// registerComposite registers the composite provider, which routes pods to
+// other providers registered in the same store.
+func registerComposite(s *providers.Store) {
+	s.Register(composite.ProviderName, func(cfg providers.InitConfig) (providers.Provider, error) {
+		c, err := composite.LoadConfig(cfg.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		return composite.NewFromConfig(c, cfg, s)
+	})
+}
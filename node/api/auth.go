@@ -0,0 +1,143 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/virtual-kubelet/virtual-kubelet/log"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// Auth authenticates and authorizes requests made to the kubelet API.
+// It is modeled after the authentication and authorization the reference
+// kubelet performs on its API.
+type Auth interface {
+	authenticator.Request
+	authorizer.RequestAttributesGetter
+	authorizer.Authorizer
+}
+
+type nodeAuth struct {
+	authenticator.Request
+	authorizer.RequestAttributesGetter
+	authorizer.Authorizer
+}
+
+// NewAuth creates an Auth which authenticates requests with authn, and
+// authorizes them with authz as requests against the given node.
+//
+// As with the reference kubelet, requests are authorized against the "proxy"
+// subresource of the node, except for metrics, stats, logs and spec requests
+// which are authorized against their own subresource.
+func NewAuth(authn authenticator.Request, authz authorizer.Authorizer, nodeName string) Auth {
+	return &nodeAuth{
+		Request:                 authn,
+		RequestAttributesGetter: nodeAttributesGetter(nodeName),
+		Authorizer:              authz,
+	}
+}
+
+type nodeAttributesGetter string
+
+func isSubpath(subpath, path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return subpath == path || (strings.HasPrefix(subpath, path) && subpath[len(path)] == '/')
+}
+
+// GetRequestAttributes maps a request to the node subresource it accesses.
+func (n nodeAttributesGetter) GetRequestAttributes(u user.Info, r *http.Request) authorizer.Attributes {
+	var verb string
+	switch r.Method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodGet, http.MethodHead:
+		verb = "get"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+	}
+
+	attrs := authorizer.AttributesRecord{
+		User:            u,
+		Verb:            verb,
+		APIVersion:      "v1",
+		Resource:        "nodes",
+		Subresource:     "proxy",
+		Name:            string(n),
+		ResourceRequest: true,
+		Path:            r.URL.Path,
+	}
+
+	switch {
+	case isSubpath(r.URL.Path, "/stats"):
+		attrs.Subresource = "stats"
+	case isSubpath(r.URL.Path, "/metrics"):
+		attrs.Subresource = "metrics"
+	case isSubpath(r.URL.Path, "/logs"):
+		attrs.Subresource = "log"
+	case isSubpath(r.URL.Path, "/spec"):
+		attrs.Subresource = "spec"
+	}
+
+	return attrs
+}
+
+// AuthHandler wraps an http.Handler so that only requests which are
+// authenticated and authorized by auth are passed on to it.
+// The authenticated user is added to the request context.
+func AuthHandler(auth Auth, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		logger := log.G(req.Context())
+
+		resp, ok, err := auth.AuthenticateRequest(req)
+		if err != nil {
+			logger.WithError(err).Debug("Unable to authenticate request")
+		}
+		if err != nil || !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		attrs := auth.GetRequestAttributes(resp.User, req)
+		logger = logger.WithFields(log.Fields{
+			"user":        attrs.GetUser().GetName(),
+			"verb":        attrs.GetVerb(),
+			"subresource": attrs.GetSubresource(),
+		})
+
+		decision, reason, err := auth.Authorize(attrs)
+		if err != nil {
+			logger.WithError(err).Error("Error authorizing request")
+			http.Error(w, fmt.Sprintf("Authorization error (user=%s, verb=%s, resource=%s, subresource=%s)", attrs.GetUser().GetName(), attrs.GetVerb(), attrs.GetResource(), attrs.GetSubresource()), http.StatusInternalServerError)
+			return
+		}
+		if decision != authorizer.DecisionAllow {
+			logger.WithField("reason", reason).Debug("Forbidden request")
+			http.Error(w, fmt.Sprintf("Forbidden (user=%s, verb=%s, resource=%s, subresource=%s)", attrs.GetUser().GetName(), attrs.GetVerb(), attrs.GetResource(), attrs.GetSubresource()), http.StatusForbidden)
+			return
+		}
+
+		h.ServeHTTP(w, req.WithContext(request.WithUser(req.Context(), resp.User)))
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	stats "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+)
+
+func TestAuthHandler(t *testing.T) {
+	authn := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		if req.Header.Get("Authorization") != "Bearer good" {
+			return nil, false, nil
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: "alice"}}, true, nil
+	})
+
+	var got authorizer.Attributes
+	authz := authorizer.AuthorizerFunc(func(a authorizer.Attributes) (authorizer.Decision, string, error) {
+		got = a
+		if a.GetSubresource() == "proxy" {
+			return authorizer.DecisionDeny, "no exec for you", nil
+		}
+		return authorizer.DecisionAllow, "", nil
+	})
+
+	var handlerUser string
+	h := AuthHandler(NewAuth(authn, authz, "vk"), http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if u, ok := request.UserFrom(req.Context()); ok {
+			handlerUser = u.GetName()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Check(t, is.Equal(do(http.MethodGet, "/stats/summary", ""), http.StatusUnauthorized))
+	assert.Check(t, is.Equal(do(http.MethodGet, "/stats/summary", "bad"), http.StatusUnauthorized))
+
+	assert.Check(t, is.Equal(do(http.MethodGet, "/stats/summary", "good"), http.StatusOK))
+	assert.Check(t, is.Equal(got.GetUser().GetName(), "alice"))
+	assert.Check(t, is.Equal(handlerUser, "alice"))
+	assert.Check(t, is.Equal(got.GetVerb(), "get"))
+	assert.Check(t, is.Equal(got.GetResource(), "nodes"))
+	assert.Check(t, is.Equal(got.GetName(), "vk"))
+	assert.Check(t, is.Equal(got.GetSubresource(), "stats"))
+
+	assert.Check(t, is.Equal(do(http.MethodPost, "/exec/default/nginx/nginx", "good"), http.StatusForbidden))
+	assert.Check(t, is.Equal(got.GetVerb(), "create"))
+	assert.Check(t, is.Equal(got.GetSubresource(), "proxy"))
+}
+
+func TestAttachPodRoutesAuthenticatesStats(t *testing.T) {
+	authn := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		if req.Header.Get("Authorization") != "Bearer good" {
+			return nil, false, nil
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: "alice"}}, true, nil
+	})
+
+	var subresource string
+	authz := authorizer.AuthorizerFunc(func(a authorizer.Attributes) (authorizer.Decision, string, error) {
+		subresource = a.GetSubresource()
+		return authorizer.DecisionAllow, "", nil
+	})
+
+	mux := http.NewServeMux()
+	AttachPodRoutes(PodHandlerConfig{
+		GetStatsSummary: func(context.Context) (*stats.Summary, error) {
+			return &stats.Summary{Node: stats.NodeStats{NodeName: "vk"}}, nil
+		},
+		Auth: NewAuth(authn, authz, "vk"),
+	}, mux, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/summary", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Check(t, is.Equal(rr.Code, http.StatusUnauthorized))
+
+	req = httptest.NewRequest(http.MethodGet, "/stats/summary", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Check(t, is.Equal(rr.Code, http.StatusOK))
+	assert.Check(t, is.Contains(rr.Body.String(), `"nodeName":"vk"`))
+	assert.Check(t, is.Equal(subresource, "stats"))
+}
@@ -16,15 +16,19 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/log"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/endpoints/request"
 	remoteutils "k8s.io/client-go/tools/remotecommand"
 	api "k8s.io/kubernetes/pkg/apis/core"
 	"k8s.io/kubernetes/pkg/kubelet/server/remotecommand"
@@ -49,13 +53,68 @@ type TermSize struct {
 	Height uint16
 }
 
+// ExecSession describes an exec session for auditing.
+type ExecSession struct {
+	// User is the name of the authenticated user, empty if requests are not authenticated.
+	User      string
+	Namespace string
+	Pod       string
+	Container string
+	Command   []string
+	TTY       bool
+	Start     time.Time
+}
+
+// ExecRecorderFunc returns a writer which the stdin, stdout and stderr streams
+// of an exec session are recorded to. The writer is closed when the session ends.
+//
+// The recording starts with a header line describing the session:
+//
+//	exec-recording v1 user="alice" namespace="default" pod="nginx" container="web" command=["sh"] tty=true start=2019-05-01T10:00:00Z
+//
+// followed by one record for each chunk of data read from stdin or written to
+// stdout or stderr. Each record is a line with the stream name, the time in
+// RFC3339 format with nanoseconds and the length of the data, followed by the
+// data itself and a newline:
+//
+//	stdin 2019-05-01T10:00:01.5Z 3
+//	ls
+//
+// If it returns an error the session is refused.
+type ExecRecorderFunc func(ctx context.Context, s ExecSession) (io.WriteCloser, error)
+
+// ContainerExecHandlerConfig is used to pass options to the container exec handler.
+type ContainerExecHandlerConfig struct {
+	// Recorder, if set, records the streams of every exec session.
+	Recorder ExecRecorderFunc
+}
+
+// ContainerExecHandlerOption configures a ContainerExecHandlerConfig
+// It is used as functional options passed to `HandleContainerExec`
+type ContainerExecHandlerOption func(*ContainerExecHandlerConfig)
+
+// WithExecRecorder sets the recorder used to record the streams of exec sessions.
+func WithExecRecorder(r ExecRecorderFunc) ContainerExecHandlerOption {
+	return func(cfg *ContainerExecHandlerConfig) {
+		cfg.Recorder = r
+	}
+}
+
 // HandleContainerExec makes an http handler func from a Provider which execs a command in a pod's container
 // Note that this handler currently depends on gorrilla/mux to get url parts as variables.
 // TODO(@cpuguy83): don't force gorilla/mux on consumers of this function
-func HandleContainerExec(h ContainerExecHandlerFunc) http.HandlerFunc {
+//
+// Every session is logged with the user, pod, container, command and duration.
+func HandleContainerExec(h ContainerExecHandlerFunc, opts ...ContainerExecHandlerOption) http.HandlerFunc {
 	if h == nil {
 		return NotImplemented
 	}
+
+	var cfg ContainerExecHandlerConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+
 	return handleError(func(w http.ResponseWriter, req *http.Request) error {
 		vars := mux.Vars(req)
 
@@ -76,10 +135,15 @@ func HandleContainerExec(h ContainerExecHandlerFunc) http.HandlerFunc {
 		idleTimeout := time.Second * 30
 		streamCreationTimeout := time.Second * 30
 
-		ctx, cancel := context.WithCancel(context.TODO())
+		ctx, cancel := context.WithCancel(log.WithLogger(context.TODO(), log.G(req.Context())))
 		defer cancel()
 
-		exec := &containerExecContext{ctx: ctx, h: h, pod: pod, namespace: namespace, container: container}
+		var user string
+		if u, ok := request.UserFrom(req.Context()); ok {
+			user = u.GetName()
+		}
+
+		exec := &containerExecContext{ctx: ctx, h: h, pod: pod, namespace: namespace, container: container, user: user, recorder: cfg.Recorder}
 		remotecommand.ServeExec(w, req, exec, "", "", container, command, streamOpts, idleTimeout, streamCreationTimeout, supportedStreamProtocols)
 
 		return nil
@@ -111,12 +175,68 @@ type containerExecContext struct {
 	h                         ContainerExecHandlerFunc
 	eio                       *execIO
 	namespace, pod, container string
+	user                      string
+	recorder                  ExecRecorderFunc
 	ctx                       context.Context
 }
 
 // ExecInContainer Implements remotecommand.Executor
 // This is called by remotecommand.ServeExec
-func (c *containerExecContext) ExecInContainer(name string, uid types.UID, container string, cmd []string, in io.Reader, out, err io.WriteCloser, tty bool, resize <-chan remoteutils.TerminalSize, timeout time.Duration) error {
+func (c *containerExecContext) ExecInContainer(name string, uid types.UID, container string, cmd []string, in io.Reader, out, err io.WriteCloser, tty bool, resize <-chan remoteutils.TerminalSize, timeout time.Duration) (retErr error) {
+	session := ExecSession{
+		User:      c.user,
+		Namespace: c.namespace,
+		Pod:       c.pod,
+		Container: c.container,
+		Command:   cmd,
+		TTY:       tty,
+		Start:     time.Now(),
+	}
+
+	logger := log.G(c.ctx).WithFields(log.Fields{
+		"user":      session.User,
+		"namespace": session.Namespace,
+		"pod":       session.Pod,
+		"container": session.Container,
+		"command":   session.Command,
+		"tty":       session.TTY,
+	})
+	logger.Info("Exec session started")
+	defer func() {
+		logger = logger.WithField("duration", time.Since(session.Start))
+		if retErr != nil {
+			logger = logger.WithError(retErr)
+		}
+		logger.Info("Exec session ended")
+	}()
+
+	if c.recorder != nil {
+		rec, recErr := c.recorder(c.ctx, session)
+		if recErr != nil {
+			return errors.Wrap(recErr, "error setting up exec session recording")
+		}
+
+		w := &recordWriter{w: rec}
+		w.writeHeader(session)
+		defer func() {
+			if werr := w.Err(); werr != nil {
+				logger.WithError(werr).Error("Error recording exec session")
+			}
+			if cerr := rec.Close(); cerr != nil {
+				logger.WithError(cerr).Error("Error closing exec session recording")
+			}
+		}()
+
+		if in != nil {
+			in = io.TeeReader(in, w.stream("stdin"))
+		}
+		if out != nil {
+			out = &teeWriteCloser{WriteCloser: out, tee: w.stream("stdout")}
+		}
+		if err != nil {
+			err = &teeWriteCloser{WriteCloser: err, tee: w.stream("stderr")}
+		}
+	}
 
 	eio := &execIO{
 		tty:    tty,
@@ -159,6 +279,74 @@ func (c *containerExecContext) ExecInContainer(name string, uid types.UID, conta
 	return c.h(c.ctx, c.namespace, c.pod, c.container, cmd, eio)
 }
 
+// recordWriter writes an exec session recording in the format described on
+// ExecRecorderFunc. stdin, stdout and stderr are copied concurrently, so
+// records are serialized.
+// Write errors are kept rather than returned so a broken recording does not
+// break the session, no more writes are made after the first error.
+type recordWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+func (r *recordWriter) writeHeader(s ExecSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, r.err = fmt.Fprintf(r.w, "exec-recording v1 user=%q namespace=%q pod=%q container=%q command=%q tty=%t start=%s\n",
+		s.User, s.Namespace, s.Pod, s.Container, s.Command, s.TTY, s.Start.UTC().Format(time.RFC3339Nano))
+}
+
+func (r *recordWriter) writeRecord(stream string, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if _, r.err = fmt.Fprintf(r.w, "%s %s %d\n", stream, time.Now().UTC().Format(time.RFC3339Nano), len(p)); r.err != nil {
+		return
+	}
+	if _, r.err = r.w.Write(p); r.err != nil {
+		return
+	}
+	_, r.err = io.WriteString(r.w, "\n")
+}
+
+func (r *recordWriter) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// stream returns a writer which records everything written to it as data of the named stream.
+func (r *recordWriter) stream(name string) io.Writer {
+	return recordStream{r: r, name: name}
+}
+
+type recordStream struct {
+	r    *recordWriter
+	name string
+}
+
+func (s recordStream) Write(p []byte) (int, error) {
+	s.r.writeRecord(s.name, p)
+	return len(p), nil
+}
+
+// teeWriteCloser copies everything written to the wrapped stream to tee.
+type teeWriteCloser struct {
+	io.WriteCloser
+	tee io.Writer
+}
+
+func (t *teeWriteCloser) Write(p []byte) (int, error) {
+	n, err := t.WriteCloser.Write(p)
+	if n > 0 {
+		t.tee.Write(p[:n])
+	}
+	return n, err
+}
+
 type execIO struct {
 	tty      bool
 	stdin    io.Reader
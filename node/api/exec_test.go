@@ -0,0 +1,131 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestExecRecorder(t *testing.T) {
+	var session ExecSession
+	rec := &closeRecorder{}
+
+	exec := &containerExecContext{
+		ctx:       context.Background(),
+		namespace: "default",
+		pod:       "nginx",
+		container: "web",
+		user:      "alice",
+		h: func(ctx context.Context, namespace, podName, containerName string, cmd []string, attach AttachIO) error {
+			in, err := ioutil.ReadAll(attach.Stdin())
+			if err != nil {
+				return err
+			}
+			_, err = attach.Stdout().Write(bytes.ToUpper(in))
+			return err
+		},
+		recorder: func(ctx context.Context, s ExecSession) (io.WriteCloser, error) {
+			session = s
+			return rec, nil
+		},
+	}
+
+	var out bytes.Buffer
+	err := exec.ExecInContainer("", "", "web", []string{"cat"}, strings.NewReader("hello"), nopWriteCloser{&out}, nil, false, nil, 0)
+	assert.NilError(t, err)
+
+	assert.Check(t, is.Equal(out.String(), "HELLO"))
+	assert.Check(t, rec.closed)
+
+	header, err := rec.ReadString('\n')
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(header, fmt.Sprintf("exec-recording v1 user=\"alice\" namespace=\"default\" pod=\"nginx\" container=\"web\" command=[\"cat\"] tty=false start=%s\n", session.Start.UTC().Format(time.RFC3339Nano))))
+
+	type record struct {
+		Stream string
+		Data   string
+	}
+	var records []record
+	for rec.Len() > 0 {
+		line, err := rec.ReadString('\n')
+		assert.NilError(t, err)
+		fields := strings.Fields(line)
+		assert.Assert(t, is.Len(fields, 3), "bad record header %q", line)
+
+		ts, err := time.Parse(time.RFC3339Nano, fields[1])
+		assert.NilError(t, err)
+		assert.Check(t, !ts.Before(session.Start.Truncate(time.Second)))
+
+		n, err := strconv.Atoi(fields[2])
+		assert.NilError(t, err)
+		data := rec.Next(n + 1)
+		assert.Assert(t, is.Len(data, n+1))
+		assert.Check(t, is.Equal(data[n], byte('\n')))
+		records = append(records, record{Stream: fields[0], Data: string(data[:n])})
+	}
+	assert.Check(t, is.DeepEqual(records, []record{{"stdin", "hello"}, {"stdout", "HELLO"}}))
+
+	assert.Check(t, is.Equal(session.User, "alice"))
+	assert.Check(t, is.Equal(session.Namespace, "default"))
+	assert.Check(t, is.Equal(session.Pod, "nginx"))
+	assert.Check(t, is.Equal(session.Container, "web"))
+	assert.Check(t, is.DeepEqual(session.Command, []string{"cat"}))
+}
+
+func TestExecRecorderError(t *testing.T) {
+	var called bool
+	exec := &containerExecContext{
+		ctx: context.Background(),
+		h: func(ctx context.Context, namespace, podName, containerName string, cmd []string, attach AttachIO) error {
+			called = true
+			return nil
+		},
+		recorder: func(ctx context.Context, s ExecSession) (io.WriteCloser, error) {
+			return nil, errors.New("disk full")
+		},
+	}
+
+	err := exec.ExecInContainer("", "", "web", []string{"sh"}, nil, nopWriteCloser{ioutil.Discard}, nil, false, nil, 0)
+	assert.Check(t, is.ErrorContains(err, "disk full"))
+	assert.Check(t, !called, "session must not start when it cannot be recorded")
+}
@@ -78,6 +78,10 @@ func httpStatusCode(err error) int {
 		return http.StatusNotFound
 	case errdefs.IsInvalidInput(err):
 		return http.StatusBadRequest
+	case errdefs.IsConflict(err):
+		return http.StatusConflict
+	case errdefs.IsUnavailable(err):
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
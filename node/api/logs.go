@@ -15,9 +15,12 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -35,15 +38,246 @@ type ContainerLogsHandlerFunc func(ctx context.Context, namespace, podName, cont
 type ContainerLogOpts struct {
 	Tail       int
 	Since      time.Duration
+	SinceTime  time.Time
 	LimitBytes int
 	Timestamps bool
+	Follow     bool
+	// Previous requests the logs of the previous instance of the container,
+	// for containers which have been restarted.
+	Previous bool
+	// IncludePrevious requests the logs of the previous instance of the
+	// container followed by the logs of the current one.
+	// See CombinedContainerLogs.
+	IncludePrevious bool
+}
+
+// IncludePreviousLogsAnnotation is the pod annotation which, when set to
+// "true", makes log requests for the pod's containers return the logs of the
+// previous instance of the container followed by those of the current one.
+//
+// It is an annotation rather than a query parameter because the API server
+// only passes the fields of v1.PodLogOptions on to the kubelet, so this is the
+// only way for `kubectl logs` users to get the combined view.
+const IncludePreviousLogsAnnotation = "virtual-kubelet.io/include-previous-logs"
+
+// parseLogOptions parses the query parameters of a container logs request, as
+// sent by the API server for the fields of v1.PodLogOptions.
+func parseLogOptions(q url.Values) (opts ContainerLogOpts, err error) {
+	opts.Tail = 10
+	if queryTail := q.Get("tailLines"); queryTail != "" {
+		opts.Tail, err = strconv.Atoi(queryTail)
+		if err != nil {
+			return opts, errdefs.AsInvalidInput(errors.Wrap(err, "could not parse \"tailLines\""))
+		}
+		if opts.Tail < 0 {
+			return opts, errdefs.InvalidInput("\"tailLines\" must be a non-negative integer")
+		}
+	}
+
+	if querySince := q.Get("sinceSeconds"); querySince != "" {
+		since, err := strconv.Atoi(querySince)
+		if err != nil {
+			return opts, errdefs.AsInvalidInput(errors.Wrap(err, "could not parse \"sinceSeconds\""))
+		}
+		if since <= 0 {
+			return opts, errdefs.InvalidInput("\"sinceSeconds\" must be a positive integer")
+		}
+		opts.Since = time.Duration(since) * time.Second
+	}
+
+	if querySinceTime := q.Get("sinceTime"); querySinceTime != "" {
+		if opts.Since != 0 {
+			return opts, errdefs.InvalidInput("only one of \"sinceSeconds\" and \"sinceTime\" may be set")
+		}
+		opts.SinceTime, err = time.Parse(time.RFC3339, querySinceTime)
+		if err != nil {
+			return opts, errdefs.AsInvalidInput(errors.Wrap(err, "could not parse \"sinceTime\""))
+		}
+	}
+
+	if queryLimit := q.Get("limitBytes"); queryLimit != "" {
+		opts.LimitBytes, err = strconv.Atoi(queryLimit)
+		if err != nil {
+			return opts, errdefs.AsInvalidInput(errors.Wrap(err, "could not parse \"limitBytes\""))
+		}
+		if opts.LimitBytes <= 0 {
+			return opts, errdefs.InvalidInput("\"limitBytes\" must be a positive integer")
+		}
+	}
+
+	for name, v := range map[string]*bool{
+		"timestamps": &opts.Timestamps,
+		"follow":     &opts.Follow,
+		"previous":   &opts.Previous,
+	} {
+		if value := q.Get(name); value != "" {
+			*v, err = strconv.ParseBool(value)
+			if err != nil {
+				return opts, errdefs.AsInvalidInput(errors.Wrapf(err, "could not parse %q", name))
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// CombinedContainerLogs wraps h so that requests with IncludePrevious set are
+// served with the logs of the previous instance of the container followed by
+// the logs of the current one. If h reports that there is no previous
+// instance, only the current logs are returned. IncludePrevious is ignored
+// when Previous is set.
+//
+// Tail and LimitBytes apply to the combined logs: the previous instance only
+// fills the lines the current one does not, and the combined stream is cut
+// off after LimitBytes. Only the current instance is followed.
+func CombinedContainerLogs(h ContainerLogsHandlerFunc) ContainerLogsHandlerFunc {
+	return func(ctx context.Context, namespace, podName, containerName string, opts ContainerLogOpts) (io.ReadCloser, error) {
+		includePrevious := opts.IncludePrevious && !opts.Previous
+		opts.IncludePrevious = false
+		if !includePrevious {
+			return h(ctx, namespace, podName, containerName, opts)
+		}
+
+		prevOpts := opts
+		prevOpts.Previous = true
+		prevOpts.Follow = false
+
+		var current io.ReadCloser
+		if opts.Tail > 0 {
+			// Count the lines the current instance contributes to the tail.
+			// When following, the current instance is fetched again below so
+			// that it can be streamed. LimitBytes is left to the combined
+			// stream so that the count is not cut short.
+			curOpts := opts
+			curOpts.Follow = false
+			curOpts.LimitBytes = 0
+			rc, err := h(ctx, namespace, podName, containerName, curOpts)
+			if err != nil {
+				return nil, err
+			}
+			b, err := ioutil.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, errors.Wrap(err, "error reading logs of current container instance")
+			}
+			prevOpts.Tail -= countLines(b)
+			if !opts.Follow {
+				current = ioutil.NopCloser(bytes.NewReader(b))
+			}
+		}
+
+		var previous io.ReadCloser
+		if opts.Tail <= 0 || prevOpts.Tail > 0 {
+			var err error
+			previous, err = h(ctx, namespace, podName, containerName, prevOpts)
+			if err != nil && !errdefs.IsNotFound(err) {
+				return nil, errors.Wrap(err, "error getting logs of previous container instance")
+			}
+		}
+
+		if current == nil {
+			var err error
+			current, err = h(ctx, namespace, podName, containerName, opts)
+			if err != nil {
+				if previous != nil {
+					previous.Close()
+				}
+				return nil, err
+			}
+		}
+
+		rc := current
+		if previous != nil {
+			rc = &multiReadCloser{Reader: io.MultiReader(previous, current), closers: []io.Closer{previous, current}}
+		}
+		if opts.LimitBytes > 0 {
+			rc = &multiReadCloser{Reader: io.LimitReader(rc, int64(opts.LimitBytes)), closers: []io.Closer{rc}}
+		}
+		return rc, nil
+	}
+}
+
+func countLines(b []byte) int {
+	n := bytes.Count(b, []byte("\n"))
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+func includePreviousLogs(ctx context.Context, getPod PodGetterFunc, namespace, name string) (bool, error) {
+	pod, err := getPod(ctx, namespace, name)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "error getting pod")
+	}
+	if pod == nil {
+		return false, nil
+	}
+	a, ok := pod.Annotations[IncludePreviousLogsAnnotation]
+	if !ok {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(a)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("Ignoring invalid %s annotation", IncludePreviousLogsAnnotation)
+		return false, nil
+	}
+	return v, nil
+}
+
+type multiReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (m *multiReadCloser) Close() error {
+	var retErr error
+	for _, c := range m.closers {
+		if err := c.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}
+	return retErr
+}
+
+// ContainerLogsHandlerConfig is used to pass options to the container logs handler.
+type ContainerLogsHandlerConfig struct {
+	// GetPod, if set, is used to look up IncludePreviousLogsAnnotation on the
+	// pod whose logs are requested.
+	GetPod PodGetterFunc
+}
+
+// ContainerLogsHandlerOption configures a ContainerLogsHandlerConfig
+// It is used as functional options passed to `HandleContainerLogs`
+type ContainerLogsHandlerOption func(*ContainerLogsHandlerConfig)
+
+// WithLogsPodGetter sets the function used to look up pods for
+// IncludePreviousLogsAnnotation.
+func WithLogsPodGetter(f PodGetterFunc) ContainerLogsHandlerOption {
+	return func(cfg *ContainerLogsHandlerConfig) {
+		cfg.GetPod = f
+	}
 }
 
 // HandleContainerLogs creates an http handler function from a provider to serve logs from a pod
-func HandleContainerLogs(h ContainerLogsHandlerFunc) http.HandlerFunc {
+//
+// If a pod getter is configured, pods annotated with
+// IncludePreviousLogsAnnotation are served the logs of the previous and current
+// instances of the container together, see CombinedContainerLogs.
+func HandleContainerLogs(h ContainerLogsHandlerFunc, opts ...ContainerLogsHandlerOption) http.HandlerFunc {
 	if h == nil {
 		return NotImplemented
 	}
+
+	var cfg ContainerLogsHandlerConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	h = CombinedContainerLogs(h)
 	return handleError(func(w http.ResponseWriter, req *http.Request) error {
 		vars := mux.Vars(req)
 		if len(vars) != 3 {
@@ -55,22 +289,17 @@ func HandleContainerLogs(h ContainerLogsHandlerFunc) http.HandlerFunc {
 		namespace := vars["namespace"]
 		pod := vars["pod"]
 		container := vars["container"]
-		tail := 10
-		q := req.URL.Query()
 
-		if queryTail := q.Get("tailLines"); queryTail != "" {
-			t, err := strconv.Atoi(queryTail)
-			if err != nil {
-				return errdefs.AsInvalidInput(errors.Wrap(err, "could not parse \"tailLines\""))
-			}
-			tail = t
+		opts, err := parseLogOptions(req.URL.Query())
+		if err != nil {
+			return err
 		}
 
-		// TODO(@cpuguy83): support v1.PodLogOptions
-		// The kubelet decoding here is not straight forward, so this needs to be disected
-
-		opts := ContainerLogOpts{
-			Tail: tail,
+		if cfg.GetPod != nil && !opts.Previous {
+			opts.IncludePrevious, err = includePreviousLogs(ctx, cfg.GetPod, namespace, pod)
+			if err != nil {
+				return err
+			}
 		}
 
 		logs, err := h(ctx, namespace, pod, container, opts)
@@ -0,0 +1,214 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseLogOptions(t *testing.T) {
+	sinceTime, err := time.Parse(time.RFC3339, "2019-05-01T10:00:00Z")
+	assert.NilError(t, err)
+
+	cases := []struct {
+		name    string
+		query   url.Values
+		opts    ContainerLogOpts
+		invalid bool
+	}{
+		{
+			name: "defaults",
+			opts: ContainerLogOpts{Tail: 10},
+		},
+		{
+			name: "all options",
+			query: url.Values{
+				"tailLines":    {"100"},
+				"sinceSeconds": {"60"},
+				"limitBytes":   {"1024"},
+				"timestamps":   {"true"},
+				"follow":       {"true"},
+				"previous":     {"true"},
+			},
+			opts: ContainerLogOpts{
+				Tail:       100,
+				Since:      time.Minute,
+				LimitBytes: 1024,
+				Timestamps: true,
+				Follow:     true,
+				Previous:   true,
+			},
+		},
+		{
+			name:  "since time",
+			query: url.Values{"sinceTime": {"2019-05-01T10:00:00Z"}},
+			opts:  ContainerLogOpts{Tail: 10, SinceTime: sinceTime},
+		},
+		{
+			name:    "since seconds and since time",
+			query:   url.Values{"sinceSeconds": {"60"}, "sinceTime": {"2019-05-01T10:00:00Z"}},
+			invalid: true,
+		},
+		{
+			name:    "negative tail",
+			query:   url.Values{"tailLines": {"-1"}},
+			invalid: true,
+		},
+		{
+			name:    "bad previous",
+			query:   url.Values{"previous": {"maybe"}},
+			invalid: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts, err := parseLogOptions(c.query)
+			if c.invalid {
+				assert.Check(t, errdefs.IsInvalidInput(err), "expected invalid input error, got: %v", err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(opts, c.opts))
+		})
+	}
+}
+
+func TestCombinedContainerLogs(t *testing.T) {
+	var calls []ContainerLogOpts
+	logs := func(hasPrevious bool) ContainerLogsHandlerFunc {
+		return func(ctx context.Context, namespace, podName, containerName string, opts ContainerLogOpts) (io.ReadCloser, error) {
+			calls = append(calls, opts)
+			if opts.Previous {
+				if !hasPrevious {
+					return nil, errdefs.NotFound("no previous container")
+				}
+				return ioutil.NopCloser(strings.NewReader("previous\n")), nil
+			}
+			return ioutil.NopCloser(strings.NewReader("current\n")), nil
+		}
+	}
+
+	read := func(h ContainerLogsHandlerFunc, opts ContainerLogOpts) string {
+		calls = nil
+		rc, err := CombinedContainerLogs(h)(context.Background(), "default", "nginx", "nginx", opts)
+		assert.NilError(t, err)
+		defer rc.Close()
+		b, err := ioutil.ReadAll(rc)
+		assert.NilError(t, err)
+		return string(b)
+	}
+
+	assert.Check(t, is.Equal(read(logs(true), ContainerLogOpts{Tail: 10}), "current\n"))
+	assert.Check(t, is.Len(calls, 1))
+
+	assert.Check(t, is.Equal(read(logs(true), ContainerLogOpts{Tail: 10, Follow: true, IncludePrevious: true}), "previous\ncurrent\n"))
+	assert.Assert(t, is.Len(calls, 3))
+	assert.Check(t, is.DeepEqual(calls[0], ContainerLogOpts{Tail: 10}))
+	assert.Check(t, is.DeepEqual(calls[1], ContainerLogOpts{Tail: 9, Previous: true}))
+	assert.Check(t, is.DeepEqual(calls[2], ContainerLogOpts{Tail: 10, Follow: true}))
+
+	assert.Check(t, is.Equal(read(logs(false), ContainerLogOpts{IncludePrevious: true}), "current\n"))
+
+	assert.Check(t, is.Equal(read(logs(true), ContainerLogOpts{Previous: true, IncludePrevious: true}), "previous\n"))
+	assert.Check(t, is.Len(calls, 1))
+}
+
+func TestCombinedContainerLogsLimits(t *testing.T) {
+	instances := map[bool][]string{
+		true:  {"p1", "p2", "p3"},
+		false: {"c1", "c2"},
+	}
+	h := func(ctx context.Context, namespace, podName, containerName string, opts ContainerLogOpts) (io.ReadCloser, error) {
+		lines := instances[opts.Previous]
+		if opts.Tail > 0 && opts.Tail < len(lines) {
+			lines = lines[len(lines)-opts.Tail:]
+		}
+		var b strings.Builder
+		for _, l := range lines {
+			b.WriteString(l + "\n")
+		}
+		return ioutil.NopCloser(strings.NewReader(b.String())), nil
+	}
+
+	read := func(opts ContainerLogOpts) string {
+		opts.IncludePrevious = true
+		rc, err := CombinedContainerLogs(h)(context.Background(), "default", "nginx", "nginx", opts)
+		assert.NilError(t, err)
+		defer rc.Close()
+		b, err := ioutil.ReadAll(rc)
+		assert.NilError(t, err)
+		return string(b)
+	}
+
+	assert.Check(t, is.Equal(read(ContainerLogOpts{}), "p1\np2\np3\nc1\nc2\n"))
+	assert.Check(t, is.Equal(read(ContainerLogOpts{Tail: 3}), "p3\nc1\nc2\n"))
+	assert.Check(t, is.Equal(read(ContainerLogOpts{Tail: 3, Follow: true}), "p3\nc1\nc2\n"))
+	assert.Check(t, is.Equal(read(ContainerLogOpts{Tail: 2}), "c1\nc2\n"))
+	assert.Check(t, is.Equal(read(ContainerLogOpts{LimitBytes: 5}), "p1\np2"))
+	assert.Check(t, is.Equal(read(ContainerLogOpts{Tail: 3, LimitBytes: 7}), "p3\nc1\nc"))
+}
+
+func TestHandleContainerLogsIncludePreviousAnnotation(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"plain": {ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plain"}},
+		"combined": {ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "combined",
+			Annotations: map[string]string{IncludePreviousLogsAnnotation: "true"},
+		}},
+	}
+	h := PodHandler(PodHandlerConfig{
+		GetContainerLogs: func(ctx context.Context, namespace, podName, containerName string, opts ContainerLogOpts) (io.ReadCloser, error) {
+			if opts.Previous {
+				return ioutil.NopCloser(strings.NewReader("previous\n")), nil
+			}
+			return ioutil.NopCloser(strings.NewReader("current\n")), nil
+		},
+		GetPod: func(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+			pod, ok := pods[name]
+			if !ok {
+				return nil, errdefs.NotFound("pod not found")
+			}
+			return pod, nil
+		},
+	}, false)
+
+	get := func(path string) string {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Check(t, is.Equal(rr.Code, http.StatusOK))
+		return rr.Body.String()
+	}
+
+	assert.Check(t, is.Equal(get("/containerLogs/default/plain/nginx"), "current\n"))
+	assert.Check(t, is.Equal(get("/containerLogs/default/combined/nginx"), "previous\ncurrent\n"))
+	assert.Check(t, is.Equal(get("/containerLogs/default/combined/nginx?previous=true"), "previous\n"))
+	assert.Check(t, is.Equal(get("/containerLogs/default/missing/nginx"), "current\n"))
+}
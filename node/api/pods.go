@@ -26,6 +26,10 @@ import (
 
 type PodListerFunc func(context.Context) ([]*v1.Pod, error)
 
+// PodGetterFunc is used to get a single pod by namespace and name.
+// It returns an errdefs.NotFound error if the pod does not exist.
+type PodGetterFunc func(ctx context.Context, namespace, name string) (*v1.Pod, error)
+
 func HandleRunningPods(getPods PodListerFunc) http.HandlerFunc {
 	scheme := runtime.NewScheme()
 	v1.SchemeBuilder.AddToScheme(scheme)
@@ -36,6 +36,17 @@ type PodHandlerConfig struct {
 	RunInContainer   ContainerExecHandlerFunc
 	GetContainerLogs ContainerLogsHandlerFunc
 	GetPods          PodListerFunc
+	// GetPod, if set, is used by the logs handler to look up
+	// IncludePreviousLogsAnnotation on pods.
+	GetPod PodGetterFunc
+	// GetStatsSummary, if set, serves /stats/summary alongside the pod routes
+	// so that, as on the reference kubelet's port, it is covered by Auth.
+	GetStatsSummary PodStatsSummaryHandlerFunc
+	// ExecRecorder, if set, records the streams of exec sessions.
+	ExecRecorder ExecRecorderFunc
+	// Auth is used to authenticate and authorize requests.
+	// If nil, all requests are allowed.
+	Auth Auth
 }
 
 // PodHandler creates an http handler for interacting with pods/containers.
@@ -47,8 +58,11 @@ func PodHandler(p PodHandlerConfig, debug bool) http.Handler {
 	if debug {
 		r.HandleFunc("/runningpods/", HandleRunningPods(p.GetPods)).Methods("GET")
 	}
-	r.HandleFunc("/containerLogs/{namespace}/{pod}/{container}", HandleContainerLogs(p.GetContainerLogs)).Methods("GET")
-	r.HandleFunc("/exec/{namespace}/{pod}/{container}", HandleContainerExec(p.RunInContainer)).Methods("POST")
+	r.HandleFunc("/containerLogs/{namespace}/{pod}/{container}", HandleContainerLogs(p.GetContainerLogs, WithLogsPodGetter(p.GetPod))).Methods("GET")
+	r.HandleFunc("/exec/{namespace}/{pod}/{container}", HandleContainerExec(p.RunInContainer, WithExecRecorder(p.ExecRecorder))).Methods("POST")
+	if p.GetStatsSummary != nil {
+		r.HandleFunc("/stats/summary", HandlePodStatsSummary(p.GetStatsSummary)).Methods("GET")
+	}
 	r.NotFoundHandler = http.HandlerFunc(NotFound)
 	return r
 }
@@ -79,7 +93,11 @@ func PodStatsSummaryHandler(f PodStatsSummaryHandlerFunc) http.Handler {
 // Callers should take care to namespace the serve mux as they see fit, however
 // these routes get called by the Kubernetes API server.
 func AttachPodRoutes(p PodHandlerConfig, mux ServeMux, debug bool) {
-	mux.Handle("/", InstrumentHandler(PodHandler(p, debug)))
+	h := PodHandler(p, debug)
+	if p.Auth != nil {
+		h = AuthHandler(p.Auth, h)
+	}
+	mux.Handle("/", InstrumentHandler(h))
 }
 
 // PodMetricsConfig stores the handlers for pod metrics routes
@@ -34,6 +34,13 @@ import (
 
 const (
 	podStatusReasonProviderFailed = "ProviderFailed"
+
+	// ReasonProviderCreateFailed is the reason used in events emitted when the provider fails to create a pod.
+	ReasonProviderCreateFailed = "ProviderCreateFailed"
+	// ReasonProviderUpdateFailed is the reason used in events emitted when the provider fails to update a pod.
+	ReasonProviderUpdateFailed = "ProviderUpdateFailed"
+	// ReasonProviderDeleteFailed is the reason used in events emitted when the provider keeps failing to delete a pod.
+	ReasonProviderDeleteFailed = "ProviderDeleteFailed"
 )
 
 func addPodAttributes(ctx context.Context, span trace.Span, pod *corev1.Pod) context.Context {
@@ -76,6 +83,7 @@ func (pc *PodController) createOrUpdatePod(ctx context.Context, pod *corev1.Pod)
 		if actual := hashPodSpec(pod.Spec); actual != expected {
 			log.G(ctx).Debugf("Pod %s exists, updating pod in provider", pp.Name)
 			if origErr := pc.provider.UpdatePod(ctx, pod); origErr != nil {
+				pc.recorder.Eventf(pod, corev1.EventTypeWarning, ReasonProviderUpdateFailed, "failed to update pod in provider: %v", origErr)
 				pc.handleProviderError(ctx, span, origErr, pod)
 				return origErr
 			}
@@ -83,6 +91,7 @@ func (pc *PodController) createOrUpdatePod(ctx context.Context, pod *corev1.Pod)
 		}
 	} else {
 		if origErr := pc.provider.CreatePod(ctx, pod); origErr != nil {
+			pc.recorder.Eventf(pod, corev1.EventTypeWarning, ReasonProviderCreateFailed, "failed to create pod in provider: %v", origErr)
 			pc.handleProviderError(ctx, span, origErr, pod)
 			return origErr
 		}
@@ -144,21 +153,20 @@ func (pc *PodController) deletePod(ctx context.Context, namespace, name string)
 	defer span.End()
 	ctx = addPodAttributes(ctx, span, pod)
 
-	var delErr error
-	if delErr = pc.provider.DeletePod(ctx, pod); delErr != nil && errors.IsNotFound(delErr) {
-		span.SetStatus(delErr)
-		return delErr
+	// A not found error means the pod is already gone from the provider, any
+	// other error is returned so the deletion is retried.
+	if err := pc.provider.DeletePod(ctx, pod); err != nil && !errdefs.IsNotFound(err) {
+		span.SetStatus(err)
+		return err
 	}
 
 	log.G(ctx).Debug("Deleted pod from provider")
 
-	if !errors.IsNotFound(delErr) {
-		if err := pc.forceDeletePodResource(ctx, namespace, name); err != nil {
-			span.SetStatus(err)
-			return err
-		}
-		log.G(ctx).Info("Deleted pod from Kubernetes")
+	if err := pc.forceDeletePodResource(ctx, namespace, name); err != nil {
+		span.SetStatus(err)
+		return err
 	}
+	log.G(ctx).Info("Deleted pod from Kubernetes")
 
 	return nil
 }
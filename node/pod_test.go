@@ -16,6 +16,7 @@ package node
 
 import (
 	"context"
+	"errors"
 	"path"
 	"testing"
 
@@ -24,7 +25,12 @@ import (
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 type mockProvider struct {
@@ -33,6 +39,9 @@ type mockProvider struct {
 	creates int
 	updates int
 	deletes int
+
+	updateErr error
+	deleteErr error
 }
 
 func (m *mockProvider) CreatePod(ctx context.Context, pod *corev1.Pod) error {
@@ -42,6 +51,9 @@ func (m *mockProvider) CreatePod(ctx context.Context, pod *corev1.Pod) error {
 }
 
 func (m *mockProvider) UpdatePod(ctx context.Context, pod *corev1.Pod) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
 	m.pods[path.Join(pod.GetNamespace(), pod.GetName())] = pod
 	m.updates++
 	return nil
@@ -64,6 +76,9 @@ func (m *mockProvider) GetPodStatus(ctx context.Context, namespace, name string)
 }
 
 func (m *mockProvider) DeletePod(ctx context.Context, p *corev1.Pod) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
 	delete(m.pods, path.Join(p.GetNamespace(), p.GetName()))
 	m.deletes++
 	return nil
@@ -293,3 +308,77 @@ func TestPodNoSpecChange(t *testing.T) {
 	assert.Check(t, is.Equal(svr.mock.creates, 1))
 	assert.Check(t, is.Equal(svr.mock.updates, 0))
 }
+
+func TestPodDeleteProviderError(t *testing.T) {
+	svr := newTestController()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+
+	_, err := svr.client.CoreV1().Pods(pod.Namespace).Create(pod)
+	assert.NilError(t, err)
+	assert.NilError(t, svr.mock.CreatePod(context.Background(), pod))
+
+	// The error is returned so the deletion is retried, and the pod is kept in Kubernetes until then.
+	svr.mock.deleteErr = errors.New("provider unavailable")
+	err = svr.deletePod(context.Background(), pod.Namespace, pod.Name)
+	assert.Check(t, is.Error(err, "provider unavailable"))
+	_, err = svr.client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	// A not found error means the pod is already gone from the provider.
+	svr.mock.deleteErr = errdefs.NotFound("gone")
+	err = svr.deletePod(context.Background(), pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	_, err = svr.client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	assert.Check(t, k8serrors.IsNotFound(err))
+}
+
+func TestPodUpdateProviderError(t *testing.T) {
+	svr := newTestController()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+
+	_, err := svr.client.CoreV1().Pods(pod.Namespace).Create(pod)
+	assert.NilError(t, err)
+	assert.NilError(t, svr.mock.CreatePod(context.Background(), pod.DeepCopy()))
+
+	svr.mock.updateErr = errors.New("provider unavailable")
+	updated := pod.DeepCopy()
+	updated.Spec.Containers[0].Image = "nginx:latest"
+	err = svr.createOrUpdatePod(context.Background(), updated)
+	assert.Check(t, is.Error(err, "provider unavailable"))
+
+	recorder := svr.recorder.(*record.FakeRecorder)
+	select {
+	case ev := <-recorder.Events:
+		assert.Check(t, is.Contains(ev, ReasonProviderUpdateFailed))
+		assert.Check(t, is.Contains(ev, "provider unavailable"))
+	default:
+		t.Fatal("expected an event to be recorded")
+	}
+}
+
+func TestPodDeleteRetriesExhausted(t *testing.T) {
+	svr := newTestController()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	now := metav1.Now()
+	pod.DeletionTimestamp = &now
+
+	_, err := svr.client.CoreV1().Pods(pod.Namespace).Create(pod)
+	assert.NilError(t, err)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NilError(t, indexer.Add(pod))
+	svr.podsLister = corev1listers.NewPodLister(indexer)
+
+	svr.syncRetriesExhausted(context.Background(), "default/nginx", errors.New("provider unavailable"))
+
+	_, err = svr.client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	assert.Check(t, k8serrors.IsNotFound(err))
+
+	recorder := svr.recorder.(*record.FakeRecorder)
+	select {
+	case ev := <-recorder.Events:
+		assert.Check(t, is.Contains(ev, ReasonProviderDeleteFailed))
+		assert.Check(t, is.Contains(ev, "provider unavailable"))
+	default:
+		t.Fatal("expected an event to be recorded")
+	}
+}
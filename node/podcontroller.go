@@ -94,6 +94,8 @@ type PodController struct {
 	client corev1client.PodsGetter
 
 	resourceManager *manager.ResourceManager
+
+	statusPollInterval time.Duration
 }
 
 // PodControllerConfig is used to configure a new PodController.
@@ -117,8 +119,17 @@ type PodControllerConfig struct {
 	ConfigMapInformer corev1informers.ConfigMapInformer
 	SecretInformer    corev1informers.SecretInformer
 	ServiceInformer   corev1informers.ServiceInformer
+
+	// StatusPollInterval is how often pod statuses are polled from providers
+	// which do not implement PodNotifier.
+	// Defaults to DefaultStatusPollInterval when zero; must not be negative.
+	StatusPollInterval time.Duration
 }
 
+// DefaultStatusPollInterval is the default interval at which pod statuses are
+// polled from providers which do not implement PodNotifier.
+const DefaultStatusPollInterval = 5 * time.Second
+
 func NewPodController(cfg PodControllerConfig) (*PodController, error) {
 	if cfg.PodClient == nil {
 		return nil, errdefs.InvalidInput("missing core client")
@@ -138,6 +149,12 @@ func NewPodController(cfg PodControllerConfig) (*PodController, error) {
 	if cfg.ServiceInformer == nil {
 		return nil, errdefs.InvalidInput("missing service informer")
 	}
+	if cfg.StatusPollInterval < 0 {
+		return nil, errdefs.InvalidInputf("invalid status poll interval %s: must not be negative", cfg.StatusPollInterval)
+	}
+	if cfg.StatusPollInterval == 0 {
+		cfg.StatusPollInterval = DefaultStatusPollInterval
+	}
 
 	rm, err := manager.NewResourceManager(cfg.PodInformer.Lister(), cfg.SecretInformer.Lister(), cfg.ConfigMapInformer.Lister(), cfg.ServiceInformer.Lister())
 	if err != nil {
@@ -152,6 +169,8 @@ func NewPodController(cfg PodControllerConfig) (*PodController, error) {
 		resourceManager: rm,
 		ready:           make(chan struct{}),
 		recorder:        cfg.EventRecorder,
+
+		statusPollInterval: cfg.StatusPollInterval,
 	}, nil
 }
 
@@ -216,6 +235,7 @@ func (pc *PodController) Run(ctx context.Context, podSyncWorkers int) error {
 
 	log.G(ctx).Info("starting workers")
 	for id := 0; id < podSyncWorkers; id++ {
+		id := id
 		go wait.Until(func() {
 			// Use the worker's "index" as its ID so we can use it for tracing.
 			pc.runWorker(ctx, strconv.Itoa(id), k8sQ)
@@ -253,7 +273,7 @@ func (pc *PodController) processNextWorkItem(ctx context.Context, workerId strin
 
 	// Add the ID of the current worker as an attribute to the current span.
 	ctx = span.WithField(ctx, "workerId", workerId)
-	return handleQueueItem(ctx, q, pc.syncHandler)
+	return handleQueueItem(ctx, q, pc.syncHandler, pc.syncRetriesExhausted)
 }
 
 // syncHandler compares the actual state with the desired, and attempts to converge the two.
@@ -292,7 +312,34 @@ func (pc *PodController) syncHandler(ctx context.Context, key string) error {
 		return nil
 	}
 	// At this point we know the Pod resource has either been created or updated (which includes being marked for deletion).
-	return pc.syncPodInProvider(ctx, pod)
+	// Work on a copy as the pod is modified on its way to the provider, and the lister's copy is shared with the informer cache.
+	return pc.syncPodInProvider(ctx, pod.DeepCopy())
+}
+
+// syncRetriesExhausted is called when syncing a pod has failed maxRetries times.
+// A pod which is being deleted would otherwise stay in Kubernetes forever if the provider keeps failing to delete it, so it
+// is deleted from Kubernetes anyway and a warning event is recorded.
+func (pc *PodController) syncRetriesExhausted(ctx context.Context, key string, syncErr error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+
+	pod, err := pc.podsLister.Pods(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.G(ctx).WithError(syncErr).Errorf("giving up deleting pod %q from the provider, it may be left running there", loggablePodNameFromCoordinates(namespace, name))
+		}
+		return
+	}
+	if pod.DeletionTimestamp == nil {
+		return
+	}
+
+	pc.recorder.Eventf(pod, corev1.EventTypeWarning, ReasonProviderDeleteFailed, "failed to delete pod in provider after %d attempts, deleting it from Kubernetes anyway: %v", maxRetries, syncErr)
+	if err := pc.forceDeletePodResource(ctx, namespace, name); err != nil {
+		log.G(ctx).WithError(err).Errorf("failed to delete pod %q from Kubernetes", loggablePodName(pod))
+	}
 }
 
 // syncPodInProvider tries and reconciles the state of a pod by comparing its Kubernetes representation and the provider's representation.
@@ -0,0 +1,92 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	testutil "github.com/virtual-kubelet/virtual-kubelet/internal/test/util"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func testPodControllerConfig() PodControllerConfig {
+	client := fake.NewSimpleClientset()
+	factory := informers.NewSharedInformerFactory(client, 0)
+
+	return PodControllerConfig{
+		PodClient:         client.CoreV1(),
+		EventRecorder:     testutil.FakeEventRecorder(5),
+		Provider:          newMockProvider(),
+		PodInformer:       factory.Core().V1().Pods(),
+		ConfigMapInformer: factory.Core().V1().ConfigMaps(),
+		SecretInformer:    factory.Core().V1().Secrets(),
+		ServiceInformer:   factory.Core().V1().Services(),
+	}
+}
+
+func TestNewPodControllerStatusPollInterval(t *testing.T) {
+	cfg := testPodControllerConfig()
+	pc, err := NewPodController(cfg)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(pc.statusPollInterval, DefaultStatusPollInterval))
+
+	cfg.StatusPollInterval = time.Second
+	pc, err = NewPodController(cfg)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(pc.statusPollInterval, time.Second))
+
+	cfg.StatusPollInterval = -time.Second
+	_, err = NewPodController(cfg)
+	assert.Check(t, errdefs.IsInvalidInput(err), err)
+}
+
+func TestProviderSyncLoopStatusPollInterval(t *testing.T) {
+	svr := newTestController()
+	svr.statusPollInterval = 10 * time.Millisecond
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NilError(t, indexer.Add(testutil.FakePodWithSingleContainer("default", "nginx", "nginx")))
+	svr.podsLister = corev1listers.NewPodLister(indexer)
+
+	q := workqueue.NewRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(0, 0, 0))
+	defer q.ShutDown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svr.providerSyncLoop(ctx, q)
+
+	// The default interval is far longer than this deadline, so a queued
+	// status update means the configured interval was honoured.
+	deadline := time.After(DefaultStatusPollInterval / 2)
+	for q.Len() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for a pod status update to be queued")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	key, _ := q.Get()
+	assert.Check(t, is.Equal(key, "default/nginx"))
+}
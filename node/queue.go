@@ -20,6 +20,7 @@ import (
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
 	"github.com/virtual-kubelet/virtual-kubelet/log"
 	"github.com/virtual-kubelet/virtual-kubelet/trace"
 	corev1 "k8s.io/api/core/v1"
@@ -33,7 +34,16 @@ const (
 
 type queueHandler func(ctx context.Context, key string) error
 
-func handleQueueItem(ctx context.Context, q workqueue.RateLimitingInterface, handler queueHandler) bool {
+// retriesExhaustedHandler is called with the last error when a key is forgotten after failing maxRetries times.
+type retriesExhaustedHandler func(ctx context.Context, key string, err error)
+
+// handleQueueItem processes the next key on the queue with handler, and decides
+// from the class of the error whether the key is retried:
+//
+// - invalid input is never retried, as retrying cannot fix it;
+// - unavailable errors are retried with backoff for as long as it takes, as the provider is expected to come back;
+// - other errors, including conflicts, are retried with backoff up to maxRetries times, after which onRetriesExhausted is called if set.
+func handleQueueItem(ctx context.Context, q workqueue.RateLimitingInterface, handler queueHandler, onRetriesExhausted retriesExhaustedHandler) bool {
 	ctx, span := trace.StartSpan(ctx, "handleQueueItem")
 	defer span.End()
 
@@ -67,6 +77,16 @@ func handleQueueItem(ctx context.Context, q workqueue.RateLimitingInterface, han
 		ctx = span.WithField(ctx, "key", key)
 		// Run the syncHandler, passing it the namespace/name string of the Pod resource to be synced.
 		if err := handler(ctx, key); err != nil {
+			if errdefs.IsInvalidInput(err) {
+				// Retrying cannot fix invalid input, so forget the key until the object changes.
+				q.Forget(key)
+				return pkgerrors.Wrapf(err, "forgetting %q due to invalid input", key)
+			}
+			if errdefs.IsUnavailable(err) {
+				log.G(ctx).WithError(err).Warnf("requeuing %q as the provider is unavailable", key)
+				q.AddRateLimited(key)
+				return nil
+			}
 			if q.NumRequeues(key) < maxRetries {
 				// Put the item back on the work queue to handle any transient errors.
 				log.G(ctx).WithError(err).Warnf("requeuing %q due to failed sync", key)
@@ -75,6 +95,9 @@ func handleQueueItem(ctx context.Context, q workqueue.RateLimitingInterface, han
 			}
 			// We've exceeded the maximum retries, so we must forget the key.
 			q.Forget(key)
+			if onRetriesExhausted != nil {
+				onRetriesExhausted(ctx, key, err)
+			}
 			return pkgerrors.Wrapf(err, "forgetting %q due to maximum retries reached", key)
 		}
 		// Finally, if no error occurs we Forget this item so it does not get queued again until another change happens.
@@ -113,7 +136,7 @@ func (pc *PodController) processPodStatusUpdate(ctx context.Context, workerID st
 	// Add the ID of the current worker as an attribute to the current span.
 	ctx = span.WithField(ctx, "workerID", workerID)
 
-	return handleQueueItem(ctx, q, pc.podStatusHandler)
+	return handleQueueItem(ctx, q, pc.podStatusHandler, nil)
 }
 
 // providerSyncLoop syncronizes pod states from the provider back to kubernetes
@@ -121,7 +144,10 @@ func (pc *PodController) processPodStatusUpdate(ctx context.Context, workerID st
 // Providers should implement async update support, even if it just means copying
 // something like this in.
 func (pc *PodController) providerSyncLoop(ctx context.Context, q workqueue.RateLimitingInterface) {
-	const sleepTime = 5 * time.Second
+	sleepTime := pc.statusPollInterval
+	if sleepTime == 0 {
+		sleepTime = DefaultStatusPollInterval
+	}
 
 	t := time.NewTimer(sleepTime)
 	defer t.Stop()
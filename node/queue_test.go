@@ -0,0 +1,80 @@
+// Copyright © 2017 The virtual-kubelet authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestHandleQueueItemRetries(t *testing.T) {
+	ctx := context.Background()
+	q := workqueue.NewRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(0, 0, 0))
+	defer q.ShutDown()
+
+	q.Add("default/nginx")
+	handleQueueItem(ctx, q, func(context.Context, string) error {
+		return errors.New("transient failure")
+	}, nil)
+	assert.Check(t, is.Equal(q.NumRequeues("default/nginx"), 1))
+
+	handleQueueItem(ctx, q, func(context.Context, string) error {
+		return errdefs.InvalidInput("bad pod")
+	}, nil)
+	assert.Check(t, is.Equal(q.NumRequeues("default/nginx"), 0))
+	assert.Check(t, is.Equal(q.Len(), 0))
+}
+
+func TestHandleQueueItemRetriesExhausted(t *testing.T) {
+	ctx := context.Background()
+	q := workqueue.NewRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(0, 0, 0))
+	defer q.ShutDown()
+
+	var exhausted []string
+	onRetriesExhausted := func(_ context.Context, key string, err error) {
+		exhausted = append(exhausted, key)
+	}
+
+	q.Add("default/nginx")
+	for i := 0; i < maxRetries; i++ {
+		handleQueueItem(ctx, q, func(context.Context, string) error {
+			return errors.New("persistent failure")
+		}, onRetriesExhausted)
+	}
+	assert.Check(t, is.Len(exhausted, 0))
+	assert.Check(t, is.Equal(q.NumRequeues("default/nginx"), maxRetries))
+
+	handleQueueItem(ctx, q, func(context.Context, string) error {
+		return errors.New("persistent failure")
+	}, onRetriesExhausted)
+	assert.Check(t, is.DeepEqual(exhausted, []string{"default/nginx"}))
+	assert.Check(t, is.Equal(q.Len(), 0))
+
+	// The provider being unavailable is retried without limit.
+	q.Add("default/nginx")
+	for i := 0; i <= maxRetries; i++ {
+		handleQueueItem(ctx, q, func(context.Context, string) error {
+			return errdefs.Unavailable("provider is down")
+		}, onRetriesExhausted)
+	}
+	assert.Check(t, is.Len(exhausted, 1))
+	assert.Check(t, is.Equal(q.Len(), 1))
+}
@@ -0,0 +1,310 @@
+// Package composite implements a provider which routes pods to one of several
+// underlying providers, while presenting them as a single virtual node.
+package composite
+
+import (
+	"context"
+	"io"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/log"
+	"github.com/virtual-kubelet/virtual-kubelet/node"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	v1 "k8s.io/api/core/v1"
+	stats "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+)
+
+// Backend is a named provider pods can be routed to.
+type Backend struct {
+	Name     string
+	Provider providers.Provider
+}
+
+// Route sends the pods matching all of its labels and annotations to a backend.
+type Route struct {
+	// Backend is the name of the backend matching pods are sent to.
+	Backend string `json:"backend"`
+	// MatchLabels are the labels a pod must have to match the route.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// MatchAnnotations are the annotations a pod must have to match the route.
+	MatchAnnotations map[string]string `json:"matchAnnotations,omitempty"`
+}
+
+func (r Route) matches(pod *v1.Pod) bool {
+	for k, v := range r.MatchLabels {
+		if pod.Labels[k] != v {
+			return false
+		}
+	}
+	for k, v := range r.MatchAnnotations {
+		if pod.Annotations[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Provider routes pods to its backends.
+//
+// New pods are sent to the backend of the first route they match, or to the
+// default backend if they match none. Once a pod has been created, all
+// operations on it go to the backend which knows about it.
+//
+// Node level information (conditions, addresses, daemon endpoints) is taken
+// from the default backend, except for capacity which is the sum of the
+// capacity of every backend.
+type Provider struct {
+	backends []Backend
+	routes   []Route
+	// def is the backend used for pods which do not match any route.
+	def Backend
+}
+
+// NotifierProvider is like Provider, but implements the PodNotifier interface.
+// It is returned by New when all backends implement node.PodNotifier.
+type NotifierProvider struct {
+	*Provider
+}
+
+// New creates a composite provider from the passed in backends.
+// defaultBackend is the name of the backend used for pods which do not match
+// any route.
+//
+// The returned provider implements node.PodNotifier only if all the backends
+// implement it.
+func New(backends []Backend, routes []Route, defaultBackend string) (providers.Provider, error) {
+	if len(backends) == 0 {
+		return nil, errdefs.InvalidInput("at least one backend is required")
+	}
+
+	p := &Provider{routes: routes}
+	names := make(map[string]bool, len(backends))
+	notifiers := true
+
+	for _, b := range backends {
+		if b.Name == "" {
+			return nil, errdefs.InvalidInput("backend name cannot be empty")
+		}
+		if b.Provider == nil {
+			return nil, errdefs.InvalidInputf("backend %q has no provider", b.Name)
+		}
+		if names[b.Name] {
+			return nil, errdefs.InvalidInputf("duplicate backend %q", b.Name)
+		}
+		names[b.Name] = true
+
+		if b.Name == defaultBackend {
+			p.def = b
+		}
+		if _, ok := b.Provider.(node.PodNotifier); !ok {
+			notifiers = false
+		}
+		p.backends = append(p.backends, b)
+	}
+
+	if p.def.Provider == nil {
+		return nil, errdefs.InvalidInputf("default backend %q is not defined", defaultBackend)
+	}
+
+	for _, b := range p.backends {
+		if b.Provider.OperatingSystem() != p.def.Provider.OperatingSystem() {
+			return nil, errdefs.InvalidInputf("backend %q runs %s pods, but the default backend runs %s pods", b.Name, b.Provider.OperatingSystem(), p.def.Provider.OperatingSystem())
+		}
+	}
+
+	for _, r := range routes {
+		if !names[r.Backend] {
+			return nil, errdefs.InvalidInputf("route refers to unknown backend %q", r.Backend)
+		}
+	}
+
+	if notifiers {
+		return &NotifierProvider{Provider: p}, nil
+	}
+	return p, nil
+}
+
+// route returns the backend new pods should be created in.
+func (p *Provider) route(pod *v1.Pod) Backend {
+	for _, r := range p.routes {
+		if r.matches(pod) {
+			return p.backend(r.Backend)
+		}
+	}
+	return p.def
+}
+
+func (p *Provider) backend(name string) Backend {
+	for _, b := range p.backends {
+		if b.Name == name {
+			return b
+		}
+	}
+	return p.def
+}
+
+// lookup returns the backend which knows about the given pod.
+func (p *Provider) lookup(ctx context.Context, namespace, name string) (Backend, *v1.Pod, error) {
+	for _, b := range p.backends {
+		pod, err := b.Provider.GetPod(ctx, namespace, name)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return Backend{}, nil, err
+		}
+		if pod != nil {
+			return b, pod, nil
+		}
+	}
+	return Backend{}, nil, errdefs.NotFoundf("pod \"%s/%s\" is not known to any backend", namespace, name)
+}
+
+func withBackend(ctx context.Context, b Backend) context.Context {
+	return log.WithLogger(ctx, log.G(ctx).WithField("backend", b.Name))
+}
+
+// CreatePod creates the pod in the backend it is routed to.
+func (p *Provider) CreatePod(ctx context.Context, pod *v1.Pod) error {
+	b := p.route(pod)
+	ctx = withBackend(ctx, b)
+	log.G(ctx).Debugf("routing pod %s/%s", pod.Namespace, pod.Name)
+	return b.Provider.CreatePod(ctx, pod)
+}
+
+// UpdatePod updates the pod in the backend which knows about it.
+func (p *Provider) UpdatePod(ctx context.Context, pod *v1.Pod) error {
+	b, _, err := p.lookup(ctx, pod.Namespace, pod.Name)
+	if err != nil {
+		return err
+	}
+	return b.Provider.UpdatePod(withBackend(ctx, b), pod)
+}
+
+// DeletePod deletes the pod from the backend which knows about it.
+func (p *Provider) DeletePod(ctx context.Context, pod *v1.Pod) error {
+	b, _, err := p.lookup(ctx, pod.Namespace, pod.Name)
+	if err != nil {
+		return err
+	}
+	return b.Provider.DeletePod(withBackend(ctx, b), pod)
+}
+
+// GetPod returns the pod from the backend which knows about it.
+func (p *Provider) GetPod(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	_, pod, err := p.lookup(ctx, namespace, name)
+	return pod, err
+}
+
+// GetPodStatus returns the pod status from the backend which knows about the pod.
+func (p *Provider) GetPodStatus(ctx context.Context, namespace, name string) (*v1.PodStatus, error) {
+	b, _, err := p.lookup(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return b.Provider.GetPodStatus(withBackend(ctx, b), namespace, name)
+}
+
+// GetPods returns the pods of all backends.
+func (p *Provider) GetPods(ctx context.Context) ([]*v1.Pod, error) {
+	var pods []*v1.Pod
+	for _, b := range p.backends {
+		ls, err := b.Provider.GetPods(withBackend(ctx, b))
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, ls...)
+	}
+	return pods, nil
+}
+
+// GetContainerLogs retrieves the logs of a container from the backend which knows about its pod.
+func (p *Provider) GetContainerLogs(ctx context.Context, namespace, podName, containerName string, opts api.ContainerLogOpts) (io.ReadCloser, error) {
+	b, _, err := p.lookup(ctx, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	return b.Provider.GetContainerLogs(withBackend(ctx, b), namespace, podName, containerName, opts)
+}
+
+// RunInContainer executes a command in a container through the backend which knows about its pod.
+func (p *Provider) RunInContainer(ctx context.Context, namespace, podName, containerName string, cmd []string, attach api.AttachIO) error {
+	b, _, err := p.lookup(ctx, namespace, podName)
+	if err != nil {
+		return err
+	}
+	return b.Provider.RunInContainer(withBackend(ctx, b), namespace, podName, containerName, cmd, attach)
+}
+
+// Capacity returns the sum of the capacity of all backends.
+func (p *Provider) Capacity(ctx context.Context) v1.ResourceList {
+	total := v1.ResourceList{}
+	for _, b := range p.backends {
+		for name, q := range b.Provider.Capacity(ctx) {
+			if cur, ok := total[name]; ok {
+				cur.Add(q)
+				total[name] = cur
+			} else {
+				total[name] = q.DeepCopy()
+			}
+		}
+	}
+	return total
+}
+
+// NodeConditions returns the node conditions of the default backend.
+func (p *Provider) NodeConditions(ctx context.Context) []v1.NodeCondition {
+	return p.def.Provider.NodeConditions(ctx)
+}
+
+// NodeAddresses returns the node addresses of the default backend.
+func (p *Provider) NodeAddresses(ctx context.Context) []v1.NodeAddress {
+	return p.def.Provider.NodeAddresses(ctx)
+}
+
+// NodeDaemonEndpoints returns the daemon endpoints of the default backend.
+func (p *Provider) NodeDaemonEndpoints(ctx context.Context) *v1.NodeDaemonEndpoints {
+	return p.def.Provider.NodeDaemonEndpoints(ctx)
+}
+
+// OperatingSystem returns the operating system shared by all backends.
+func (p *Provider) OperatingSystem() string {
+	return p.def.Provider.OperatingSystem()
+}
+
+// GetStatsSummary merges the pod stats of all backends which expose stats.
+// Node stats are taken from the default backend if it exposes stats.
+func (p *Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, error) {
+	res := &stats.Summary{}
+	var found bool
+
+	for _, b := range p.backends {
+		mp, ok := b.Provider.(providers.PodMetricsProvider)
+		if !ok {
+			continue
+		}
+		found = true
+
+		s, err := mp.GetStatsSummary(withBackend(ctx, b))
+		if err != nil {
+			return nil, err
+		}
+		if b.Name == p.def.Name {
+			res.Node = s.Node
+		}
+		res.Pods = append(res.Pods, s.Pods...)
+	}
+
+	if !found {
+		return nil, errdefs.NotFound("none of the backends expose stats")
+	}
+	return res, nil
+}
+
+// NotifyPods passes the notifier on to every backend.
+func (p *NotifierProvider) NotifyPods(ctx context.Context, notifier func(*v1.Pod)) {
+	for _, b := range p.backends {
+		b.Provider.(node.PodNotifier).NotifyPods(ctx, notifier)
+	}
+}
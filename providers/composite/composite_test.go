@@ -0,0 +1,132 @@
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	testutil "github.com/virtual-kubelet/virtual-kubelet/internal/test/util"
+	"github.com/virtual-kubelet/virtual-kubelet/node"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	"github.com/virtual-kubelet/virtual-kubelet/providers/conformance"
+	"github.com/virtual-kubelet/virtual-kubelet/providers/mock"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func newTestMock(t *testing.T) *mock.MockProvider {
+	p, err := mock.NewMockProviderMockConfig(mock.MockConfig{CPU: "2", Memory: "4Gi", Pods: "10"}, "vk", "Linux", "127.0.0.1", 10250)
+	assert.NilError(t, err)
+	return p
+}
+
+func newTestComposite(t *testing.T) (providers.Provider, *mock.MockProvider, *mock.MockProvider) {
+	poc, wasm := newTestMock(t), newTestMock(t)
+	p, err := New(
+		[]Backend{{Name: "poc", Provider: poc}, {Name: "wasm", Provider: wasm}},
+		[]Route{{Backend: "wasm", MatchLabels: map[string]string{"runtime": "wasm"}}},
+		"poc",
+	)
+	assert.NilError(t, err)
+	return p, poc, wasm
+}
+
+func TestCompositeConformance(t *testing.T) {
+	conformance.Run(t, conformance.Config{
+		NewProvider: func(t *testing.T) providers.Provider {
+			p, _, _ := newTestComposite(t)
+			return p
+		},
+	})
+}
+
+func TestCompositeRouting(t *testing.T) {
+	ctx := context.Background()
+	p, poc, wasm := newTestComposite(t)
+
+	def := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	routed := testutil.FakePodWithSingleContainer("default", "hello", "hello.wasm")
+	routed.Labels = map[string]string{"runtime": "wasm"}
+
+	assert.NilError(t, p.CreatePod(ctx, def))
+	assert.NilError(t, p.CreatePod(ctx, routed))
+
+	_, err := poc.GetPod(ctx, def.Namespace, def.Name)
+	assert.NilError(t, err)
+	_, err = wasm.GetPod(ctx, def.Namespace, def.Name)
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	_, err = wasm.GetPod(ctx, routed.Namespace, routed.Name)
+	assert.NilError(t, err)
+	_, err = poc.GetPod(ctx, routed.Namespace, routed.Name)
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	pods, err := p.GetPods(ctx)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(pods, 2))
+
+	// Operations on existing pods go to the backend holding the pod, even if its labels changed.
+	routed.Labels = nil
+	assert.NilError(t, p.DeletePod(ctx, routed))
+	_, err = wasm.GetPod(ctx, routed.Namespace, routed.Name)
+	assert.Check(t, errdefs.IsNotFound(err))
+}
+
+func TestCompositeCapacity(t *testing.T) {
+	p, _, _ := newTestComposite(t)
+
+	capacity := p.Capacity(context.Background())
+	cpu := capacity[v1.ResourceCPU]
+	memory := capacity[v1.ResourceMemory]
+	pods := capacity[v1.ResourcePods]
+	assert.Check(t, is.Equal(cpu.String(), "4"))
+	assert.Check(t, is.Equal(memory.String(), "8Gi"))
+	assert.Check(t, is.Equal(pods.String(), "20"))
+}
+
+func TestCompositeNotifier(t *testing.T) {
+	p, _, _ := newTestComposite(t)
+	_, ok := p.(node.PodNotifier)
+	assert.Check(t, ok, "composite of notifying providers should implement PodNotifier")
+
+	v0, err := mock.NewMockV0ProviderMockConfig(mock.MockConfig{}, "vk", "Linux", "127.0.0.1", 10250)
+	assert.NilError(t, err)
+	p, err = New([]Backend{{Name: "poc", Provider: newTestMock(t)}, {Name: "v0", Provider: v0}}, nil, "poc")
+	assert.NilError(t, err)
+	_, ok = p.(node.PodNotifier)
+	assert.Check(t, !ok, "composite with a non-notifying backend should not implement PodNotifier")
+}
+
+func TestCompositeInvalid(t *testing.T) {
+	b := Backend{Name: "poc", Provider: newTestMock(t)}
+
+	_, err := New(nil, nil, "poc")
+	assert.Check(t, errdefs.IsInvalidInput(err))
+
+	_, err = New([]Backend{b}, nil, "missing")
+	assert.Check(t, errdefs.IsInvalidInput(err))
+
+	_, err = New([]Backend{b, b}, nil, "poc")
+	assert.Check(t, errdefs.IsInvalidInput(err))
+
+	_, err = New([]Backend{b}, []Route{{Backend: "missing"}}, "poc")
+	assert.Check(t, errdefs.IsInvalidInput(err))
+}
+
+func TestCompositeFromConfigRejectsComposite(t *testing.T) {
+	s := providers.NewStore()
+	var initialized bool
+	s.Register(ProviderName, func(providers.InitConfig) (providers.Provider, error) {
+		initialized = true
+		return nil, nil
+	})
+
+	cfg := Config{
+		Default:  "nested",
+		Backends: []BackendConfig{{Name: "nested", Provider: ProviderName}},
+	}
+	_, err := NewFromConfig(cfg, providers.InitConfig{}, s)
+	assert.Check(t, errdefs.IsInvalidInput(err), "expected invalid input error, got: %v", err)
+	assert.Check(t, !initialized)
+}
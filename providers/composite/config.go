@@ -0,0 +1,83 @@
+package composite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+)
+
+// ProviderName is the name the composite provider is registered under.
+// A composite provider cannot be used as a backend of another one.
+const ProviderName = "composite"
+
+// Config is the configuration file format of the composite provider.
+//
+//	{
+//	  "default": "mock",
+//	  "backends": [
+//	    {"name": "mock", "provider": "mock", "configPath": "/etc/vk/mock.json"},
+//	    {"name": "wasm", "provider": "mock", "configPath": "/etc/vk/wasm.json"}
+//	  ],
+//	  "routes": [
+//	    {"backend": "wasm", "matchLabels": {"runtime": "wasm"}}
+//	  ]
+//	}
+type Config struct {
+	// Default is the name of the backend pods matching no route are sent to.
+	Default  string          `json:"default"`
+	Backends []BackendConfig `json:"backends"`
+	Routes   []Route         `json:"routes,omitempty"`
+}
+
+// BackendConfig configures a backend from a registered provider.
+type BackendConfig struct {
+	// Name is the name routes refer to the backend by.
+	Name string `json:"name"`
+	// Provider is the name the provider is registered under.
+	Provider string `json:"provider"`
+	// ConfigPath is the provider config passed to the provider.
+	ConfigPath string `json:"configPath,omitempty"`
+}
+
+// LoadConfig reads the composite provider configuration at the given path.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, errors.Wrap(err, "error reading composite provider config")
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, errdefs.AsInvalidInput(errors.Wrap(err, "error parsing composite provider config"))
+	}
+	return cfg, nil
+}
+
+// NewFromConfig initializes the backends described by cfg from the providers
+// registered in the store and creates a composite provider out of them.
+// Every backend gets the passed in init config, with the config path replaced
+// by the one of the backend.
+func NewFromConfig(cfg Config, initConfig providers.InitConfig, s *providers.Store) (providers.Provider, error) {
+	backends := make([]Backend, 0, len(cfg.Backends))
+	for _, bc := range cfg.Backends {
+		if bc.Provider == ProviderName {
+			return nil, errdefs.InvalidInputf("backend %q: the %s provider cannot be used as a backend", bc.Name, ProviderName)
+		}
+		initFunc := s.Get(bc.Provider)
+		if initFunc == nil {
+			return nil, errdefs.NotFoundf("backend %q: provider %q is not registered", bc.Name, bc.Provider)
+		}
+
+		c := initConfig
+		c.ConfigPath = bc.ConfigPath
+		p, err := initFunc(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error initializing backend %q", bc.Name)
+		}
+		backends = append(backends, Backend{Name: bc.Name, Provider: p})
+	}
+	return New(backends, cfg.Routes, cfg.Default)
+}
@@ -0,0 +1,374 @@
+// Package conformance implements a test suite which checks that a provider
+// behaves the way the virtual-kubelet core logic expects it to.
+//
+// Provider implementations can run the suite from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, conformance.Config{
+//			NewProvider: func(t *testing.T) providers.Provider {
+//				return newMyProvider(t)
+//			},
+//		})
+//	}
+//
+// The pod controller acts on the errdefs class of provider errors, so the suite
+// checks that providers classify their errors:
+//
+//   - NotFound for pods the provider does not know about.
+//   - InvalidInput for pods the provider cannot accept. These are not retried.
+//   - Unavailable while the provider's backend cannot be reached. These are
+//     retried indefinitely. This is only checked when Config.MakeUnavailable
+//     is set, since there is no generic way to take a backend down.
+//
+// Conflict errors are not checked. Whether a request conflicts depends on the
+// provider's backend, and the core only uses the class to pick the HTTP status
+// code of kubelet API errors.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	"github.com/virtual-kubelet/virtual-kubelet/node"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	testNamespace = "conformance"
+	testNodeName  = "conformance-node"
+)
+
+// Config configures a run of the conformance suite.
+type Config struct {
+	// NewProvider is called at the start of every test to get the provider
+	// under test. Each call is expected to return a provider which does not
+	// know about any pods.
+	// This field is required.
+	NewProvider func(t *testing.T) providers.Provider
+
+	// Timeout is how long to wait for asynchronous operations, such as the
+	// pod controller handing a pod over to the provider, to complete.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// SkipExec disables the checks for RunInContainer.
+	SkipExec bool
+
+	// MakeUnavailable, if set, makes the backend of p unreachable until the
+	// returned function is called. It is used to check that the provider
+	// returns errdefs.Unavailable errors in the meantime.
+	MakeUnavailable func(t *testing.T, p providers.Provider) (restore func())
+}
+
+// Run runs the conformance suite against the provider returned by cfg.NewProvider.
+func Run(t *testing.T, cfg Config) {
+	if cfg.NewProvider == nil {
+		t.Fatal("conformance: missing NewProvider")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	s := &suite{cfg: cfg}
+
+	t.Run("CreatePod", s.testCreatePod)
+	t.Run("UpdatePod", s.testUpdatePod)
+	t.Run("DeletePod", s.testDeletePod)
+	t.Run("NotFound", s.testNotFound)
+	t.Run("InvalidInput", s.testInvalidInput)
+	if cfg.MakeUnavailable != nil {
+		t.Run("Unavailable", s.testUnavailable)
+	}
+	t.Run("CancelledContext", s.testCancelledContext)
+	t.Run("GetContainerLogs", s.testGetContainerLogs)
+	if !cfg.SkipExec {
+		t.Run("RunInContainer", s.testRunInContainer)
+	}
+	t.Run("NodeStatus", s.testNodeStatus)
+	t.Run("PodController", s.testPodController)
+}
+
+type suite struct {
+	cfg Config
+}
+
+func (s *suite) testCreatePod(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("create")
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+
+	got, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	assert.Assert(t, got != nil, "GetPod returned a nil pod for a created pod")
+	assert.Check(t, is.Equal(got.Namespace, pod.Namespace))
+	assert.Check(t, is.Equal(got.Name, pod.Name))
+
+	status, err := p.GetPodStatus(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	assert.Assert(t, status != nil, "GetPodStatus returned a nil status for a created pod")
+	assert.Check(t, status.Phase != "", "pod status has no phase")
+
+	pods, err := p.GetPods(ctx)
+	assert.NilError(t, err)
+	assert.Check(t, containsPod(pods, pod), "GetPods does not list the created pod")
+}
+
+func (s *suite) testUpdatePod(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("update")
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+
+	updated := pod.DeepCopy()
+	updated.Spec.Containers[0].Image = "busybox:latest"
+	assert.NilError(t, p.UpdatePod(ctx, updated))
+
+	got, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(got.Spec.Containers[0].Image, "busybox:latest"))
+}
+
+func (s *suite) testDeletePod(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("delete")
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+	assert.NilError(t, p.DeletePod(ctx, pod.DeepCopy()))
+
+	_, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsNotFound(err), "expected a not found error after deletion, got: %v", err)
+
+	pods, err := p.GetPods(ctx)
+	assert.NilError(t, err)
+	assert.Check(t, !containsPod(pods, pod), "GetPods still lists the deleted pod")
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("missing")
+
+	_, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsNotFound(err), "GetPod: expected a not found error, got: %v", err)
+
+	_, err = p.GetPodStatus(ctx, pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsNotFound(err), "GetPodStatus: expected a not found error, got: %v", err)
+
+	err = p.DeletePod(ctx, pod)
+	assert.Check(t, errdefs.IsNotFound(err), "DeletePod: expected a not found error, got: %v", err)
+}
+
+func (s *suite) testInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("invalid")
+	pod.Namespace = ""
+
+	err := p.CreatePod(ctx, pod.DeepCopy())
+	assert.Check(t, errdefs.IsInvalidInput(err), "CreatePod: expected an invalid input error for a pod without a namespace, got: %v", err)
+}
+
+func (s *suite) testUnavailable(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("unavailable")
+
+	restore := s.cfg.MakeUnavailable(t, p)
+
+	err := p.CreatePod(ctx, pod.DeepCopy())
+	assert.Check(t, errdefs.IsUnavailable(err), "CreatePod: expected an unavailable error, got: %v", err)
+
+	_, err = p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsUnavailable(err), "GetPod: expected an unavailable error, got: %v", err)
+
+	restore()
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+}
+
+func (s *suite) testCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("cancelled")
+
+	err := p.CreatePod(ctx, pod.DeepCopy())
+	assert.Check(t, err != nil, "CreatePod succeeded with a cancelled context")
+
+	_, err = p.GetPod(context.Background(), pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsNotFound(err), "pod was created with a cancelled context")
+}
+
+func (s *suite) testGetContainerLogs(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("logs")
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+
+	logs, err := p.GetContainerLogs(ctx, pod.Namespace, pod.Name, pod.Spec.Containers[0].Name, api.ContainerLogOpts{Tail: 10})
+	assert.NilError(t, err)
+	assert.Assert(t, logs != nil, "GetContainerLogs returned a nil reader")
+	defer logs.Close()
+
+	_, err = io.Copy(ioutil.Discard, logs)
+	assert.NilError(t, err)
+}
+
+func (s *suite) testRunInContainer(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+	pod := newTestPod("exec")
+
+	assert.NilError(t, p.CreatePod(ctx, pod.DeepCopy()))
+
+	attach := &testAttachIO{stdout: &nopWriteCloser{}, stderr: &nopWriteCloser{}}
+	err := p.RunInContainer(ctx, pod.Namespace, pod.Name, pod.Spec.Containers[0].Name, []string{"true"}, attach)
+	assert.NilError(t, err)
+}
+
+func (s *suite) testNodeStatus(t *testing.T) {
+	ctx := context.Background()
+	p := s.cfg.NewProvider(t)
+
+	assert.Check(t, providers.ValidOperatingSystems[p.OperatingSystem()], "unsupported operating system %q", p.OperatingSystem())
+	assert.Check(t, len(p.Capacity(ctx)) > 0, "provider reports no capacity")
+	assert.Check(t, p.NodeDaemonEndpoints(ctx) != nil, "provider reports nil daemon endpoints")
+
+	var ready bool
+	for _, c := range p.NodeConditions(ctx) {
+		if c.Type == corev1.NodeReady {
+			ready = true
+		}
+	}
+	assert.Check(t, ready, "provider does not report a %s node condition", corev1.NodeReady)
+}
+
+// testPodController runs the provider behind a pod controller backed by a fake
+// clientset and checks that pods are handed over to the provider as they are
+// created and deleted in Kubernetes.
+func (s *suite) testPodController(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := s.cfg.NewProvider(t)
+	client := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+
+	pc, err := node.NewPodController(node.PodControllerConfig{
+		PodClient:         client.CoreV1(),
+		PodInformer:       informerFactory.Core().V1().Pods(),
+		EventRecorder:     record.NewFakeRecorder(100),
+		Provider:          p,
+		ConfigMapInformer: informerFactory.Core().V1().ConfigMaps(),
+		SecretInformer:    informerFactory.Core().V1().Secrets(),
+		ServiceInformer:   informerFactory.Core().V1().Services(),
+	})
+	assert.NilError(t, err)
+
+	informerFactory.Start(ctx.Done())
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- pc.Run(ctx, 1)
+	}()
+
+	select {
+	case <-pc.Ready():
+	case err := <-chErr:
+		t.Fatalf("pod controller exited before becoming ready: %v", err)
+	case <-time.After(s.cfg.Timeout):
+		t.Fatal("timed out waiting for the pod controller to become ready")
+	}
+
+	pod := newTestPod("controller")
+	_, err = client.CoreV1().Pods(pod.Namespace).Create(pod)
+	assert.NilError(t, err)
+
+	s.waitFor(t, "pod to be created in the provider", func() bool {
+		got, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+		return err == nil && got != nil
+	})
+
+	assert.NilError(t, client.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{}))
+
+	s.waitFor(t, "pod to be deleted from the provider", func() bool {
+		_, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+		return errdefs.IsNotFound(err)
+	})
+}
+
+func (s *suite) waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func newTestPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+			UID:       types.UID(testNamespace + "-" + name),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: testNodeName,
+			Containers: []corev1.Container{
+				{
+					Name:  name,
+					Image: "nginx:latest",
+				},
+			},
+		},
+	}
+}
+
+func containsPod(pods []*corev1.Pod, pod *corev1.Pod) bool {
+	for _, p := range pods {
+		if p.Namespace == pod.Namespace && p.Name == pod.Name {
+			return true
+		}
+	}
+	return false
+}
+
+type testAttachIO struct {
+	stdout, stderr io.WriteCloser
+}
+
+func (a *testAttachIO) Stdin() io.Reader            { return bytes.NewReader(nil) }
+func (a *testAttachIO) Stdout() io.WriteCloser      { return a.stdout }
+func (a *testAttachIO) Stderr() io.WriteCloser      { return a.stderr }
+func (a *testAttachIO) TTY() bool                   { return false }
+func (a *testAttachIO) Resize() <-chan api.TermSize { return nil }
+
+type nopWriteCloser struct {
+	bytes.Buffer
+}
+
+func (*nopWriteCloser) Close() error { return nil }
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
@@ -51,6 +52,19 @@ type MockV0Provider struct {
 	config             MockConfig
 	startTime          time.Time
 	notifier           func(*v1.Pod)
+
+	// mu protects pods, script and transitionStops, which may be accessed
+	// concurrently by the pod controller workers and by scripted status
+	// transitions.
+	mu     sync.Mutex
+	script mockScript
+	// transitionStops holds, for each pod going through status transitions, a
+	// channel which is closed to stop them.
+	transitionStops map[string]chan struct{}
+
+	// stop is closed by Close to stop the status transitions of all pods.
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // MockProvider is like MockV0Provider, but implements the PodNotifier interface
@@ -88,7 +102,10 @@ func NewMockV0ProviderMockConfig(config MockConfig, nodeName, operatingSystem st
 		// By default notifier is set to a function which is a no-op. In the event we've implemented the PodNotifier interface,
 		// it will be set, and then we'll call a real underlying implementation.
 		// This makes it easier in the sense we don't need to wrap each method.
-		notifier: func(*v1.Pod) {},
+		notifier:        func(*v1.Pod) {},
+		script:          newMockScript(),
+		transitionStops: make(map[string]chan struct{}),
+		stop:            make(chan struct{}),
 	}
 
 	return &provider, nil
@@ -130,7 +147,7 @@ func loadConfig(providerConfig, nodeName string) (config MockConfig, err error)
 	configMap := map[string]MockConfig{}
 	err = json.Unmarshal(data, &configMap)
 	if err != nil {
-		return config, err
+		return config, errdefs.AsInvalidInput(err)
 	}
 	if _, exist := configMap[nodeName]; exist {
 		config = configMap[nodeName]
@@ -146,13 +163,13 @@ func loadConfig(providerConfig, nodeName string) (config MockConfig, err error)
 	}
 
 	if _, err = resource.ParseQuantity(config.CPU); err != nil {
-		return config, fmt.Errorf("Invalid CPU value %v", config.CPU)
+		return config, errdefs.InvalidInputf("Invalid CPU value %v", config.CPU)
 	}
 	if _, err = resource.ParseQuantity(config.Memory); err != nil {
-		return config, fmt.Errorf("Invalid memory value %v", config.Memory)
+		return config, errdefs.InvalidInputf("Invalid memory value %v", config.Memory)
 	}
 	if _, err = resource.ParseQuantity(config.Pods); err != nil {
-		return config, fmt.Errorf("Invalid pods value %v", config.Pods)
+		return config, errdefs.InvalidInputf("Invalid pods value %v", config.Pods)
 	}
 	return config, nil
 }
@@ -172,45 +189,36 @@ func (p *MockV0Provider) CreatePod(ctx context.Context, pod *v1.Pod) error {
 		return err
 	}
 
-	now := metav1.NewTime(time.Now())
-	pod.Status = v1.PodStatus{
-		Phase:     v1.PodRunning,
-		HostIP:    "1.2.3.4",
-		PodIP:     "5.6.7.8",
-		StartTime: &now,
-		Conditions: []v1.PodCondition{
-			{
-				Type:   v1.PodInitialized,
-				Status: v1.ConditionTrue,
-			},
-			{
-				Type:   v1.PodReady,
-				Status: v1.ConditionTrue,
-			},
-			{
-				Type:   v1.PodScheduled,
-				Status: v1.ConditionTrue,
-			},
-		},
+	if err := p.runScript(ctx, CreatePodOp); err != nil {
+		return err
 	}
 
-	for _, container := range pod.Spec.Containers {
-		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
-			Name:         container.Name,
-			Image:        container.Image,
-			Ready:        true,
-			RestartCount: 0,
-			State: v1.ContainerState{
-				Running: &v1.ContainerStateRunning{
-					StartedAt: now,
-				},
-			},
-		})
-	}
+	now := metav1.NewTime(time.Now())
+	// Keep our own copy, the caller may keep using theirs.
+	pod = pod.DeepCopy()
 
+	p.mu.Lock()
+	transitions := p.script.transitions
+	if len(transitions) == 0 {
+		setPodPhase(pod, v1.PodRunning, "", "", now)
+	} else {
+		setPodPhase(pod, v1.PodPending, "", "", now)
+	}
 	p.pods[key] = pod
+	p.stopTransitions(key)
+	var stop chan struct{}
+	if len(transitions) > 0 {
+		stop = make(chan struct{})
+		p.transitionStops[key] = stop
+	}
+	p.mu.Unlock()
+
 	p.notifier(pod)
 
+	if stop != nil {
+		go p.runTransitions(key, stop, transitions)
+	}
+
 	return nil
 }
 
@@ -229,7 +237,16 @@ func (p *MockV0Provider) UpdatePod(ctx context.Context, pod *v1.Pod) error {
 		return err
 	}
 
+	if err := p.runScript(ctx, UpdatePodOp); err != nil {
+		return err
+	}
+
+	pod = pod.DeepCopy()
+
+	p.mu.Lock()
 	p.pods[key] = pod
+	p.mu.Unlock()
+
 	p.notifier(pod)
 
 	return nil
@@ -250,23 +267,38 @@ func (p *MockV0Provider) DeletePod(ctx context.Context, pod *v1.Pod) (err error)
 		return err
 	}
 
+	if err := p.runScript(ctx, DeletePodOp); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
 	if _, exists := p.pods[key]; !exists {
+		p.mu.Unlock()
 		return errdefs.NotFound("pod not found")
 	}
+	delete(p.pods, key)
+	p.stopTransitions(key)
+	p.mu.Unlock()
 
+	// The pod may be the one handed out by GetPod, which other goroutines can
+	// still be reading, so only modify a copy.
+	pod = pod.DeepCopy()
 	now := metav1.Now()
-	delete(p.pods, key)
 	pod.Status.Phase = v1.PodSucceeded
 	pod.Status.Reason = "MockProviderPodDeleted"
 
 	for idx := range pod.Status.ContainerStatuses {
+		var startedAt metav1.Time
+		if running := pod.Status.ContainerStatuses[idx].State.Running; running != nil {
+			startedAt = running.StartedAt
+		}
 		pod.Status.ContainerStatuses[idx].Ready = false
 		pod.Status.ContainerStatuses[idx].State = v1.ContainerState{
 			Terminated: &v1.ContainerStateTerminated{
 				Message:    "Mock provider terminated container upon deletion",
 				FinishedAt: now,
 				Reason:     "MockProviderPodContainerDeleted",
-				StartedAt:  pod.Status.ContainerStatuses[idx].State.Running.StartedAt,
+				StartedAt:  startedAt,
 			},
 		}
 	}
@@ -289,13 +321,25 @@ func (p *MockV0Provider) GetPod(ctx context.Context, namespace, name string) (po
 
 	log.G(ctx).Infof("receive GetPod %q", name)
 
+	if err := p.runScript(ctx, GetPodOp); err != nil {
+		return nil, err
+	}
+
+	return p.getPod(namespace, name)
+}
+
+// getPod looks up a pod in memory without running any scripted behavior.
+func (p *MockV0Provider) getPod(namespace, name string) (*v1.Pod, error) {
 	key, err := buildKeyFromNames(namespace, name)
 	if err != nil {
 		return nil, err
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if pod, ok := p.pods[key]; ok {
-		return pod, nil
+		return pod.DeepCopy(), nil
 	}
 	return nil, errdefs.NotFoundf("pod \"%s/%s\" is not known to the provider", namespace, name)
 }
@@ -309,6 +353,22 @@ func (p *MockV0Provider) GetContainerLogs(ctx context.Context, namespace, podNam
 	ctx = addAttributes(ctx, span, namespaceKey, namespace, nameKey, podName, containerNameKey, containerName)
 
 	log.G(ctx).Info("receive GetContainerLogs %q", podName)
+
+	if opts.Previous {
+		// Mock containers are never restarted, so there is no previous instance
+		// unless a restart count was set on the pod.
+		pod, err := p.getPod(namespace, podName)
+		if err != nil {
+			return nil, err
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == containerName && cs.RestartCount > 0 {
+				return ioutil.NopCloser(strings.NewReader("")), nil
+			}
+		}
+		return nil, errdefs.NotFoundf("previous terminated container %q in pod %q not found", containerName, podName)
+	}
+
 	return ioutil.NopCloser(strings.NewReader("")), nil
 }
 
@@ -336,7 +396,11 @@ func (p *MockV0Provider) GetPodStatus(ctx context.Context, namespace, name strin
 
 	log.G(ctx).Infof("receive GetPodStatus %q", name)
 
-	pod, err := p.GetPod(ctx, namespace, name)
+	if err := p.runScript(ctx, GetPodStatusOp); err != nil {
+		return nil, err
+	}
+
+	pod, err := p.getPod(namespace, name)
 	if err != nil {
 		return nil, err
 	}
@@ -351,10 +415,17 @@ func (p *MockV0Provider) GetPods(ctx context.Context) ([]*v1.Pod, error) {
 
 	log.G(ctx).Info("receive GetPods")
 
+	if err := p.runScript(ctx, GetPodsOp); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	var pods []*v1.Pod
 
 	for _, pod := range p.pods {
-		pods = append(pods, pod)
+		pods = append(pods, pod.DeepCopy())
 	}
 
 	return pods, nil
@@ -378,13 +449,16 @@ func (p *MockV0Provider) NodeConditions(ctx context.Context) []v1.NodeCondition
 	ctx, span := trace.StartSpan(ctx, "NodeConditions")
 	defer span.End()
 
+	// The conditions never change, so they have not transitioned since the provider started.
+	lastTransitionTime := metav1.NewTime(p.startTime)
+
 	// TODO: Make this configurable
 	return []v1.NodeCondition{
 		{
 			Type:               "Ready",
 			Status:             v1.ConditionTrue,
 			LastHeartbeatTime:  metav1.Now(),
-			LastTransitionTime: metav1.Now(),
+			LastTransitionTime: lastTransitionTime,
 			Reason:             "KubeletReady",
 			Message:            "kubelet is ready.",
 		},
@@ -392,7 +466,7 @@ func (p *MockV0Provider) NodeConditions(ctx context.Context) []v1.NodeCondition
 			Type:               "OutOfDisk",
 			Status:             v1.ConditionFalse,
 			LastHeartbeatTime:  metav1.Now(),
-			LastTransitionTime: metav1.Now(),
+			LastTransitionTime: lastTransitionTime,
 			Reason:             "KubeletHasSufficientDisk",
 			Message:            "kubelet has sufficient disk space available",
 		},
@@ -400,7 +474,7 @@ func (p *MockV0Provider) NodeConditions(ctx context.Context) []v1.NodeCondition
 			Type:               "MemoryPressure",
 			Status:             v1.ConditionFalse,
 			LastHeartbeatTime:  metav1.Now(),
-			LastTransitionTime: metav1.Now(),
+			LastTransitionTime: lastTransitionTime,
 			Reason:             "KubeletHasSufficientMemory",
 			Message:            "kubelet has sufficient memory available",
 		},
@@ -408,7 +482,7 @@ func (p *MockV0Provider) NodeConditions(ctx context.Context) []v1.NodeCondition
 			Type:               "DiskPressure",
 			Status:             v1.ConditionFalse,
 			LastHeartbeatTime:  metav1.Now(),
-			LastTransitionTime: metav1.Now(),
+			LastTransitionTime: lastTransitionTime,
 			Reason:             "KubeletHasNoDiskPressure",
 			Message:            "kubelet has no disk pressure",
 		},
@@ -416,7 +490,7 @@ func (p *MockV0Provider) NodeConditions(ctx context.Context) []v1.NodeCondition
 			Type:               "NetworkUnavailable",
 			Status:             v1.ConditionFalse,
 			LastHeartbeatTime:  metav1.Now(),
-			LastTransitionTime: metav1.Now(),
+			LastTransitionTime: lastTransitionTime,
 			Reason:             "RouteCreated",
 			Message:            "RouteController created a route",
 		},
@@ -476,6 +550,15 @@ func (p *MockV0Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, e
 		StartTime: metav1.NewTime(p.startTime),
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		// nodeRxBytes and nodeTxBytes will be populated with the sum of the network traffic across all pods.
+		nodeRxBytes uint64
+		nodeTxBytes uint64
+	)
+
 	// Populate the Summary object with dummy stats for each pod known by this provider.
 	for _, pod := range p.pods {
 		var (
@@ -483,6 +566,8 @@ func (p *MockV0Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, e
 			totalUsageNanoCores uint64
 			// totalUsageBytes will be populated with the sum of the values of UsageBytes computed across all containers in the pod.
 			totalUsageBytes uint64
+			// totalFsUsedBytes will be populated with the sum of the rootfs and logs usage across all containers in the pod.
+			totalFsUsedBytes uint64
 		)
 
 		// Create a PodStats object to populate with pod stats.
@@ -505,6 +590,10 @@ func (p *MockV0Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, e
 			// The value should fit a uint32 in order to avoid overflows later on when computing pod stats.
 			dummyUsageBytes := uint64(rand.Uint32())
 			totalUsageBytes += dummyUsageBytes
+			// Create dummy values to be used as the filesystem usage of the container and of its logs.
+			dummyRootfsBytes := uint64(rand.Uint32())
+			dummyLogsBytes := uint64(rand.Uint32())
+			totalFsUsedBytes += dummyRootfsBytes + dummyLogsBytes
 			// Append a ContainerStats object containing the dummy stats to the PodStats object.
 			pss.Containers = append(pss.Containers, stats.ContainerStats{
 				Name:      container.Name,
@@ -517,9 +606,23 @@ func (p *MockV0Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, e
 					Time:       time,
 					UsageBytes: &dummyUsageBytes,
 				},
+				Rootfs: &stats.FsStats{
+					Time:      time,
+					UsedBytes: &dummyRootfsBytes,
+				},
+				Logs: &stats.FsStats{
+					Time:      time,
+					UsedBytes: &dummyLogsBytes,
+				},
 			})
 		}
 
+		// Create dummy values to be used as the network traffic of the pod.
+		dummyRxBytes := uint64(rand.Uint32())
+		dummyTxBytes := uint64(rand.Uint32())
+		nodeRxBytes += dummyRxBytes
+		nodeTxBytes += dummyTxBytes
+
 		// Populate the CPU and RAM stats for the pod and append the PodsStats object to the Summary object to be returned.
 		pss.CPU = &stats.CPUStats{
 			Time:           time,
@@ -529,13 +632,43 @@ func (p *MockV0Provider) GetStatsSummary(ctx context.Context) (*stats.Summary, e
 			Time:       time,
 			UsageBytes: &totalUsageBytes,
 		}
+		pss.EphemeralStorage = &stats.FsStats{
+			Time:      time,
+			UsedBytes: &totalFsUsedBytes,
+		}
+		pss.Network = newNetworkStats(time, dummyRxBytes, dummyTxBytes)
 		res.Pods = append(res.Pods, pss)
 	}
 
+	res.Node.Network = newNetworkStats(time, nodeRxBytes, nodeTxBytes)
+
 	// Return the dummy stats.
 	return res, nil
 }
 
+// newNetworkStats returns network stats for a single eth0 interface.
+func newNetworkStats(time metav1.Time, rxBytes, txBytes uint64) *stats.NetworkStats {
+	iface := stats.InterfaceStats{
+		Name:    "eth0",
+		RxBytes: &rxBytes,
+		TxBytes: &txBytes,
+	}
+	return &stats.NetworkStats{
+		Time:           time,
+		InterfaceStats: iface,
+		Interfaces:     []stats.InterfaceStats{iface},
+	}
+}
+
+// Close stops the scripted status transitions of all pods.
+// The provider should not be used after it is closed.
+func (p *MockV0Provider) Close() error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	return nil
+}
+
 // NotifyPods is called to set a pod notifier callback function. This should be called before any operations are done
 // within the provider.
 func (p *MockProvider) NotifyPods(ctx context.Context, notifier func(*v1.Pod)) {
@@ -549,11 +682,11 @@ func buildKeyFromNames(namespace string, name string) (string, error) {
 // buildKey is a helper for building the "key" for the providers pod store.
 func buildKey(pod *v1.Pod) (string, error) {
 	if pod.ObjectMeta.Namespace == "" {
-		return "", fmt.Errorf("pod namespace not found")
+		return "", errdefs.InvalidInput("pod namespace not found")
 	}
 
 	if pod.ObjectMeta.Name == "" {
-		return "", fmt.Errorf("pod name not found")
+		return "", errdefs.InvalidInput("pod name not found")
 	}
 
 	return buildKeyFromNames(pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
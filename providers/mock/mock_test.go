@@ -1,5 +1,21 @@
 package mock
 
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/virtual-kubelet/virtual-kubelet/errdefs"
+	testutil "github.com/virtual-kubelet/virtual-kubelet/internal/test/util"
+	"github.com/virtual-kubelet/virtual-kubelet/node/api"
+	"github.com/virtual-kubelet/virtual-kubelet/providers"
+	"github.com/virtual-kubelet/virtual-kubelet/providers/conformance"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // We can guarantee the right interfaces are implemented inside of by putting casts in place. We must do the verification
 // that a given type *does not* implement a given interface in this test.
 // Cannot implement this due to:  https://github.com/virtual-kubelet/virtual-kubelet/issues/632
@@ -10,3 +26,220 @@ func TestMockLegacyInterface(t *testing.T) {
 	assert.Assert(t, !ok)
 }
 */
+
+func newTestMockProvider(t *testing.T) *MockProvider {
+	p, err := NewMockProviderMockConfig(MockConfig{}, "vk", "Linux", "127.0.0.1", 10250)
+	assert.NilError(t, err)
+	return p
+}
+
+func TestMockConformance(t *testing.T) {
+	conformance.Run(t, conformance.Config{
+		NewProvider: func(t *testing.T) providers.Provider {
+			return newTestMockProvider(t)
+		},
+		MakeUnavailable: func(t *testing.T, p providers.Provider) func() {
+			mp := p.(*MockProvider)
+			ops := []MockOperation{CreatePodOp, UpdatePodOp, DeletePodOp, GetPodOp, GetPodStatusOp, GetPodsOp}
+			for _, op := range ops {
+				mp.SetError(op, errdefs.Unavailable("mock backend is down"))
+			}
+			return func() {
+				for _, op := range ops {
+					mp.SetError(op, nil)
+				}
+			}
+		},
+	})
+}
+
+func TestMockScriptedError(t *testing.T) {
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+
+	p.SetError(CreatePodOp, errdefs.InvalidInput("scripted failure"))
+	err := p.CreatePod(ctx, pod)
+	assert.Check(t, errdefs.IsInvalidInput(err))
+
+	_, err = p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	p.SetError(CreatePodOp, nil)
+	assert.NilError(t, p.CreatePod(ctx, pod))
+	_, err = p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+}
+
+func TestMockScriptedLatency(t *testing.T) {
+	p := newTestMockProvider(t)
+	p.SetLatency(GetPodsOp, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.GetPods(ctx)
+	assert.Check(t, is.Equal(err, context.DeadlineExceeded))
+}
+
+func TestMockStatusTransitions(t *testing.T) {
+	p := newTestMockProvider(t)
+	defer p.Close()
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+
+	notified := make(chan v1.PodPhase, 10)
+	p.NotifyPods(ctx, func(pod *v1.Pod) {
+		notified <- pod.Status.Phase
+	})
+
+	p.SetStatusTransitions(
+		MockPodStatusTransition{After: time.Millisecond, Phase: v1.PodRunning},
+		MockPodStatusTransition{After: time.Millisecond, Phase: v1.PodSucceeded},
+	)
+	assert.NilError(t, p.CreatePod(ctx, pod))
+
+	for _, expected := range []v1.PodPhase{v1.PodPending, v1.PodRunning, v1.PodSucceeded} {
+		select {
+		case phase := <-notified:
+			assert.Check(t, is.Equal(phase, expected))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for pod to reach phase %q", expected)
+		}
+	}
+
+	status, err := p.GetPodStatus(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(status.ContainerStatuses[0].State.Terminated.Reason, "Completed"))
+}
+
+func TestMockStatusTransitionsStop(t *testing.T) {
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	transitions := []MockPodStatusTransition{{After: time.Hour, Phase: v1.PodRunning}}
+
+	p.SetStatusTransitions(transitions...)
+	assert.NilError(t, p.CreatePod(ctx, pod))
+
+	key, err := buildKey(pod)
+	assert.NilError(t, err)
+	p.mu.Lock()
+	stop := p.transitionStops[key]
+	p.mu.Unlock()
+	assert.Assert(t, stop != nil)
+
+	assert.NilError(t, p.DeletePod(ctx, pod))
+	select {
+	case <-stop:
+	default:
+		t.Fatal("expected the transitions of a deleted pod to be stopped")
+	}
+
+	assert.NilError(t, p.Close())
+	assert.NilError(t, p.Close())
+	done := make(chan struct{})
+	go func() {
+		p.runTransitions(key, make(chan struct{}), transitions)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected transitions to stop once the provider is closed")
+	}
+}
+
+func TestMockGetPodReturnsCopy(t *testing.T) {
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	assert.NilError(t, p.CreatePod(ctx, pod))
+
+	got, err := p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	got.Status.Phase = v1.PodFailed
+
+	pods, err := p.GetPods(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(pods, 1))
+	assert.Check(t, is.Equal(pods[0].Status.Phase, v1.PodRunning))
+	pods[0].Status.Phase = v1.PodFailed
+
+	got, err = p.GetPod(ctx, pod.Namespace, pod.Name)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(got.Status.Phase, v1.PodRunning))
+}
+
+func TestMockInvalidPod(t *testing.T) {
+	p := newTestMockProvider(t)
+	pod := testutil.FakePodWithSingleContainer("", "nginx", "nginx")
+
+	err := p.CreatePod(context.Background(), pod)
+	assert.Check(t, errdefs.IsInvalidInput(err))
+}
+
+func TestMockStatsSummary(t *testing.T) {
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	assert.NilError(t, p.CreatePod(ctx, pod))
+
+	summary, err := p.GetStatsSummary(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(summary.Pods, 1))
+	assert.Check(t, summary.Node.Network != nil)
+
+	ps := summary.Pods[0]
+	assert.Assert(t, is.Len(ps.Containers, 1))
+	c := ps.Containers[0]
+	assert.Assert(t, c.Rootfs != nil && c.Logs != nil)
+	assert.Assert(t, ps.EphemeralStorage != nil)
+	assert.Check(t, is.Equal(*ps.EphemeralStorage.UsedBytes, *c.Rootfs.UsedBytes+*c.Logs.UsedBytes))
+	assert.Assert(t, ps.Network != nil)
+	assert.Check(t, is.Equal(ps.Network.Name, "eth0"))
+	assert.Check(t, is.Len(ps.Network.Interfaces, 1))
+}
+
+func TestMockConditionTransitionTimes(t *testing.T) {
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+
+	created := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	setPodPhase(pod, v1.PodPending, "", "", created)
+
+	running := metav1.NewTime(created.Add(time.Minute))
+	setPodPhase(pod, v1.PodRunning, "", "", running)
+
+	synced := metav1.NewTime(running.Add(time.Minute))
+	setPodPhase(pod, v1.PodRunning, "", "", synced)
+
+	times := make(map[v1.PodConditionType]metav1.Time)
+	for _, c := range pod.Status.Conditions {
+		times[c.Type] = c.LastTransitionTime
+	}
+	assert.Check(t, is.DeepEqual(times[v1.PodScheduled], created))
+	assert.Check(t, is.DeepEqual(times[v1.PodReady], running))
+	assert.Check(t, is.DeepEqual(times[v1.PodInitialized], running))
+
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	first := p.NodeConditions(ctx)
+	second := p.NodeConditions(ctx)
+	for i := range first {
+		assert.Check(t, is.DeepEqual(first[i].LastTransitionTime, second[i].LastTransitionTime))
+	}
+}
+
+func TestMockContainerLogsPrevious(t *testing.T) {
+	p := newTestMockProvider(t)
+	ctx := context.Background()
+	pod := testutil.FakePodWithSingleContainer("default", "nginx", "nginx")
+	assert.NilError(t, p.CreatePod(ctx, pod))
+
+	_, err := p.GetContainerLogs(ctx, pod.Namespace, pod.Name, "nginx", api.ContainerLogOpts{Previous: true})
+	assert.Check(t, errdefs.IsNotFound(err), "expected not found error, got: %v", err)
+
+	rc, err := api.CombinedContainerLogs(p.GetContainerLogs)(ctx, pod.Namespace, pod.Name, "nginx", api.ContainerLogOpts{IncludePrevious: true})
+	assert.NilError(t, err)
+	assert.NilError(t, rc.Close())
+}
@@ -0,0 +1,265 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MockOperation identifies a provider method whose behavior can be scripted.
+type MockOperation string
+
+// Operations which can be scripted with SetLatency and SetError.
+const (
+	CreatePodOp    MockOperation = "CreatePod"
+	UpdatePodOp    MockOperation = "UpdatePod"
+	DeletePodOp    MockOperation = "DeletePod"
+	GetPodOp       MockOperation = "GetPod"
+	GetPodStatusOp MockOperation = "GetPodStatus"
+	GetPodsOp      MockOperation = "GetPods"
+)
+
+// MockPodStatusTransition describes a phase change applied to a pod some time
+// after it has been created in the provider.
+type MockPodStatusTransition struct {
+	// After is how long to wait, from the previous transition (or from pod
+	// creation for the first one), before applying this transition.
+	After   time.Duration
+	Phase   v1.PodPhase
+	Reason  string
+	Message string
+}
+
+// mockScript holds the scripted behavior of a mock provider.
+type mockScript struct {
+	latencies   map[MockOperation]time.Duration
+	errors      map[MockOperation]error
+	transitions []MockPodStatusTransition
+}
+
+func newMockScript() mockScript {
+	return mockScript{
+		latencies: make(map[MockOperation]time.Duration),
+		errors:    make(map[MockOperation]error),
+	}
+}
+
+// SetLatency makes every subsequent call to the given operation block for d
+// before being handled. The wait is aborted if the call's context is done.
+// A zero duration removes the latency.
+func (p *MockV0Provider) SetLatency(op MockOperation, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d == 0 {
+		delete(p.script.latencies, op)
+		return
+	}
+	p.script.latencies[op] = d
+}
+
+// SetError makes every subsequent call to the given operation fail with err.
+// A nil error restores the normal behavior.
+func (p *MockV0Provider) SetError(op MockOperation, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		delete(p.script.errors, op)
+		return
+	}
+	p.script.errors[op] = err
+}
+
+// SetStatusTransitions sets the phase changes applied to pods created after
+// this call.
+//
+// When no transitions are set (the default), pods are reported as running as
+// soon as they are created. Otherwise pods start in the pending phase and go
+// through each transition in order, notifying the pod controller of every
+// change. The transitions of a pod stop when it is deleted or when the provider
+// is closed.
+func (p *MockV0Provider) SetStatusTransitions(transitions ...MockPodStatusTransition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.script.transitions = append([]MockPodStatusTransition(nil), transitions...)
+}
+
+// runScript applies the latency and error scripted for the given operation.
+// Like a real provider, it refuses to do any work once the context is done.
+func (p *MockV0Provider) runScript(ctx context.Context, op MockOperation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	latency := p.script.latencies[op]
+	err := p.script.errors[op]
+	p.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return err
+}
+
+// runTransitions walks the pod stored under key through the passed in
+// transitions.
+// It stops as soon as stop or the provider's stop channel is closed, which
+// happens when the pod is deleted or replaced, or when the provider is closed.
+func (p *MockV0Provider) runTransitions(key string, stop chan struct{}, transitions []MockPodStatusTransition) {
+	defer func() {
+		p.mu.Lock()
+		if p.transitionStops[key] == stop {
+			delete(p.transitionStops, key)
+		}
+		p.mu.Unlock()
+	}()
+
+	for _, t := range transitions {
+		timer := time.NewTimer(t.After)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-p.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		select {
+		case <-stop:
+			// The pod was deleted or replaced while we were waiting for the lock.
+			p.mu.Unlock()
+			return
+		default:
+		}
+		// Stored pods are never modified in place, so pods passed to the
+		// notifier are not changed after the fact.
+		pod := p.pods[key].DeepCopy()
+		setPodPhase(pod, t.Phase, t.Reason, t.Message, metav1.Now())
+		p.pods[key] = pod
+		p.mu.Unlock()
+
+		p.notifier(pod)
+	}
+}
+
+// stopTransitions stops the status transitions of the pod stored under key,
+// if there are any. It must be called with mu held.
+func (p *MockV0Provider) stopTransitions(key string) {
+	if stop, ok := p.transitionStops[key]; ok {
+		close(stop)
+		delete(p.transitionStops, key)
+	}
+}
+
+// setPodPhase sets the status of the pod and of its containers to match the given phase.
+func setPodPhase(pod *v1.Pod, phase v1.PodPhase, reason, message string, now metav1.Time) {
+	startTime := pod.Status.StartTime
+	if startTime == nil {
+		startTime = &now
+	}
+
+	previous := make(map[string]v1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		previous[cs.Name] = cs
+	}
+
+	ready := v1.ConditionFalse
+	if phase == v1.PodRunning {
+		ready = v1.ConditionTrue
+	}
+
+	previousConditions := make(map[v1.PodConditionType]v1.PodCondition, len(pod.Status.Conditions))
+	for _, c := range pod.Status.Conditions {
+		previousConditions[c.Type] = c
+	}
+
+	conditions := []v1.PodCondition{
+		{
+			Type:   v1.PodInitialized,
+			Status: ready,
+		},
+		{
+			Type:   v1.PodReady,
+			Status: ready,
+		},
+		{
+			Type:   v1.PodScheduled,
+			Status: v1.ConditionTrue,
+		},
+	}
+	// Only move the transition time when the condition's status actually changes.
+	for i := range conditions {
+		conditions[i].LastTransitionTime = now
+		if prev, ok := previousConditions[conditions[i].Type]; ok && prev.Status == conditions[i].Status && !prev.LastTransitionTime.IsZero() {
+			conditions[i].LastTransitionTime = prev.LastTransitionTime
+		}
+	}
+
+	pod.Status = v1.PodStatus{
+		Phase:      phase,
+		Reason:     reason,
+		Message:    message,
+		HostIP:     "1.2.3.4",
+		PodIP:      "5.6.7.8",
+		StartTime:  startTime,
+		Conditions: conditions,
+	}
+
+	for _, container := range pod.Spec.Containers {
+		cs := v1.ContainerStatus{
+			Name:         container.Name,
+			Image:        container.Image,
+			Ready:        phase == v1.PodRunning,
+			RestartCount: previous[container.Name].RestartCount,
+		}
+
+		startedAt := now
+		if running := previous[container.Name].State.Running; running != nil {
+			startedAt = running.StartedAt
+		}
+
+		switch phase {
+		case v1.PodPending:
+			cs.State.Waiting = &v1.ContainerStateWaiting{
+				Reason: "ContainerCreating",
+			}
+		case v1.PodRunning:
+			cs.State.Running = &v1.ContainerStateRunning{
+				StartedAt: startedAt,
+			}
+		case v1.PodSucceeded, v1.PodFailed:
+			exitCode, terminatedReason := int32(0), "Completed"
+			if phase == v1.PodFailed {
+				exitCode, terminatedReason = 1, "Error"
+			}
+			if reason != "" {
+				terminatedReason = reason
+			}
+			cs.State.Terminated = &v1.ContainerStateTerminated{
+				ExitCode:   exitCode,
+				Reason:     terminatedReason,
+				Message:    message,
+				StartedAt:  startedAt,
+				FinishedAt: now,
+			}
+		}
+
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, cs)
+	}
+}